@@ -0,0 +1,174 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"math"
+	"time"
+)
+
+// Animation is a piece being tweened across the screen. fromX, fromY are screen coordinates (see SquareCenter) of
+// the piece's center when the animation started. A sliding piece ends on the center of the square it now occupies.
+// A captured piece ends in its slot in the column of taken pieces, which DrawUI works out.
+type Animation struct {
+	piece    ChessPiece
+	fromX    float64
+	fromY    float64
+	captured bool
+	start    time.Time
+	duration time.Duration
+}
+
+// Base animation durations at an animationSpeed of 1
+const (
+	MoveAnimDuration     = 220 * time.Millisecond
+	CaptureAnimDuration  = 400 * time.Millisecond
+	SnapBackAnimDuration = 160 * time.Millisecond
+)
+
+// Progress returns how far along the animation is, from 0 to 1, eased out so pieces settle into place
+func (a *Animation) Progress() float64 {
+	if a.duration <= 0 {
+		return 1
+	}
+	t := math.Min(float64(time.Since(a.start))/float64(a.duration), 1)
+	return 1 - math.Pow(1-t, 3)
+}
+
+// Animate starts tweening piece from the screen coordinates fromX, fromY. Does nothing when animations are off.
+func (g *Game) Animate(piece ChessPiece, fromX, fromY float64, captured bool, duration time.Duration) {
+	if g.animationSpeed <= 0 {
+		return
+	}
+	g.animations = append(g.animations, Animation{
+		piece:    piece,
+		fromX:    fromX,
+		fromY:    fromY,
+		captured: captured,
+		start:    time.Now(),
+		duration: time.Duration(float64(duration) / g.animationSpeed),
+	})
+	// the static piece layer has to be redrawn without the sliding piece
+	g.scheduleDraw = true
+}
+
+// UpdateAnimations drops finished animations, redrawing the static pieces when a sliding piece has come to rest
+func (g *Game) UpdateAnimations() {
+	running := g.animations[:0]
+	for _, a := range g.animations {
+		if a.Progress() < 1 {
+			running = append(running, a)
+		} else {
+			g.scheduleDraw = true
+		}
+	}
+	g.animations = running
+}
+
+// IsSliding reports whether piece is drawn by DrawAnimations instead of DrawStaticPieces
+func (g *Game) IsSliding(piece ChessPiece) bool {
+	for _, a := range g.animations {
+		if a.piece == piece && !a.captured {
+			return true
+		}
+	}
+	return false
+}
+
+// CaptureAnimation returns the running animation of a taken piece on its way to the side column, if there is one
+func (g *Game) CaptureAnimation(piece ChessPiece) *Animation {
+	for i := range g.animations {
+		if g.animations[i].piece == piece && g.animations[i].captured {
+			return &g.animations[i]
+		}
+	}
+	return nil
+}
+
+// DrawAnimations draws sliding pieces to movingImage
+func (g *Game) DrawAnimations() {
+	for i := range g.animations {
+		a := &g.animations[i]
+		if a.captured {
+			continue
+		}
+		toX, toY := g.SquareCenter(a.piece.Row(), a.piece.Col())
+		t := a.Progress()
+		// the same size as the pieces on the board
+		scale := PieceScale * g.layout.BoardScale()
+		half := PieceImageSize * scale / 2
+		opPiece := &ebiten.DrawImageOptions{}
+		opPiece.GeoM.Scale(scale, scale)
+		opPiece.GeoM.Translate(a.fromX+(toX-a.fromX)*t-half, a.fromY+(toY-a.fromY)*t-half)
+		opPiece.Filter = Filter
+		g.movingImage.DrawImage(a.piece.Image(), opPiece)
+	}
+}
+
+// PlayMove makes move through MakeMoveIfLegal and animates the result. dragged is true when the moving piece is
+// already drawn at selectedLocation (carried by the mouse or keyboard). A dragged piece that is rejected
+// snaps back to its square, while a piece that wasn't dragged (ex. a typed move) slides to its destination.
+// Any other piece that changed squares is animated too: a taken piece heads to the side column and a castling
+// rook slides next to its king. Legal moves are added to the game record, see RecordMove.
+func (g *Game) PlayMove(move Move, dragged bool) bool {
+	before := make(map[ChessPiece][2]int, len(g.pieces))
+	for _, piece := range g.pieces {
+		before[piece] = [2]int{piece.Row(), piece.Col()}
+	}
+	taken := len(g.captured)
+	mover := g.PieceOn(move.From[0], move.From[1])
+	san := g.SAN(move)
+
+	legal := g.MakeMoveIfLegal(move)
+
+	if !legal {
+		if dragged && mover != nil {
+			g.Animate(mover, g.selectedLocation[0], g.selectedLocation[1], false, SnapBackAnimDuration)
+		}
+		return false
+	}
+	g.RecordMove(san)
+
+	// positions are worked out after the move, since the board may have flipped to the other player
+	for _, piece := range g.pieces {
+		from, ok := before[piece]
+		if !ok {
+			// a promoted piece wasn't on the board before, it comes from the pawn's square
+			from = move.From
+		}
+		moved := piece == mover || !ok
+		if (piece.Row() == from[0] && piece.Col() == from[1]) || (moved && dragged) {
+			continue
+		}
+		fromX, fromY := g.SquareCenter(from[0], from[1])
+		g.Animate(piece, fromX, fromY, false, MoveAnimDuration)
+	}
+	for _, piece := range g.captured[taken:] {
+		fromX, fromY := g.SquareCenter(piece.Row(), piece.Col())
+		g.Animate(piece, fromX, fromY, true, CaptureAnimDuration)
+	}
+	return true
+}
+
+// DrawTakenPiece draws a taken piece to uiImage in its slot of the side column at x, y. A piece that was just
+// captured is drawn on its way there instead, shrinking from board size and fading in as it arrives.
+func (g *Game) DrawTakenPiece(piece ChessPiece, x, y float64) {
+	op := &ebiten.DrawImageOptions{}
+	slotScale := TakenPieceSize * g.layout.Scale / PieceImageSize
+	if a := g.CaptureAnimation(piece); a != nil {
+		t := a.Progress()
+		boardScale := PieceScale * g.layout.BoardScale()
+		scale := boardScale + (slotScale-boardScale)*t
+		// tween the center of the piece
+		half := TakenPieceSize * g.layout.Scale / 2
+		centerX := a.fromX + (x+half-a.fromX)*t
+		centerY := a.fromY + (y+half-a.fromY)*t
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(centerX-PieceImageSize*scale/2, centerY-PieceImageSize*scale/2)
+		op.ColorM.Scale(1, 1, 1, 0.4+0.6*t)
+	} else {
+		op.GeoM.Scale(slotScale, slotScale)
+		op.GeoM.Translate(x, y)
+	}
+	op.Filter = Filter
+	g.uiImage.DrawImage(piece.Image(), op)
+}
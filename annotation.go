@@ -0,0 +1,209 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Annotation is an arrow or circled square drawn with the right mouse button. An annotation whose from and to
+// squares are the same is drawn as a circle. colorCode is the letter used by the PGN %cal and %csl commands.
+type Annotation struct {
+	colorCode byte
+	fromRow   int
+	fromCol   int
+	toRow     int
+	toCol     int
+}
+
+const (
+	AnnotationAlpha     = 0.8
+	ArrowShaftWidth     = 22.0
+	ArrowHeadWidth      = 60.0
+	ArrowHeadLength     = 46.0
+	ArrowStartOffset    = 36.0
+	CircleOuterRadius   = 60.0
+	CircleRingThickness = 8.0
+)
+
+// annotationColors follows the common convention for %cal/%csl: Green, Red, Blue and Yellow
+var annotationColors = map[byte]color.RGBA{
+	'G': {R: 0x15, G: 0x78, B: 0x1b, A: 0xff},
+	'R': {R: 0x88, G: 0x20, B: 0x20, A: 0xff},
+	'B': {R: 0x00, G: 0x30, B: 0x88, A: 0xff},
+	'Y': {R: 0xe6, G: 0x8f, B: 0x00, A: 0xff},
+}
+
+// emptySubImage is a white pixel used as the source image for filling vector paths
+var emptySubImage = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(color.White)
+	return img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+}()
+
+// AnnotationColorCode picks the annotation color from the held modifier keys.
+// No modifier is green, Shift is red, Alt is blue and Shift+Alt is yellow.
+func AnnotationColorCode() byte {
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	alt := ebiten.IsKeyPressed(ebiten.KeyAlt)
+	switch {
+	case shift && alt:
+		return 'Y'
+	case shift:
+		return 'R'
+	case alt:
+		return 'B'
+	default:
+		return 'G'
+	}
+}
+
+// ToggleAnnotation adds the annotation, or removes it if the same arrow/circle is already drawn in the same color.
+// Drawing over an existing annotation with a different color recolors it.
+func (g *Game) ToggleAnnotation(a Annotation) {
+	for i, existing := range g.annotations {
+		if existing.fromRow == a.fromRow && existing.fromCol == a.fromCol &&
+			existing.toRow == a.toRow && existing.toCol == a.toCol {
+			if existing.colorCode == a.colorCode {
+				g.annotations = append(g.annotations[:i], g.annotations[i+1:]...)
+			} else {
+				g.annotations[i].colorCode = a.colorCode
+			}
+			return
+		}
+	}
+	g.annotations = append(g.annotations, a)
+}
+
+// UpdateAnnotations tracks right-click-drag over the board. onBoard is whether the cursor is over the board.
+// The square under the cursor when the right button goes down is the start of the arrow, and the square
+// under the cursor when it comes back up is the end. A left click clears everything.
+func (g *Game) UpdateAnnotations(onBoard bool) {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.annotations = g.annotations[:0]
+		g.annotationStart = [2]int{-1, -1}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && onBoard {
+		g.annotationStart = [2]int{g.selectedRow, g.selectedCol}
+	}
+
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonRight) && g.annotationStart[0] != -1 {
+		if onBoard {
+			g.ToggleAnnotation(Annotation{
+				colorCode: AnnotationColorCode(),
+				fromRow:   g.annotationStart[0],
+				fromCol:   g.annotationStart[1],
+				toRow:     g.selectedRow,
+				toCol:     g.selectedCol,
+			})
+		}
+		g.annotationStart = [2]int{-1, -1}
+	}
+}
+
+// DrawAnnotations renders arrows and circles to annotationImage, which is drawn in board space between the
+// pieces and the UI. The arrow currently being dragged out with the right mouse button is included.
+func (g *Game) DrawAnnotations() {
+	g.annotationImage.Clear()
+
+	for _, a := range g.annotations {
+		g.drawAnnotation(a)
+	}
+
+	if g.annotationStart[0] != -1 && (g.annotationStart[0] != g.selectedRow || g.annotationStart[1] != g.selectedCol) {
+		g.drawAnnotation(Annotation{
+			colorCode: AnnotationColorCode(),
+			fromRow:   g.annotationStart[0],
+			fromCol:   g.annotationStart[1],
+			toRow:     g.selectedRow,
+			toCol:     g.selectedCol,
+		})
+	}
+}
+
+func (g *Game) drawAnnotation(a Annotation) {
+	var path vector.Path
+	fromX, fromY := BoardSquareCenter(a.fromRow, a.fromCol)
+	toX, toY := BoardSquareCenter(a.toRow, a.toCol)
+
+	if a.fromRow == a.toRow && a.fromCol == a.toCol {
+		// ring: the inner circle is cut out of the outer one by the even-odd fill rule
+		inner := float32(CircleOuterRadius - CircleRingThickness)
+		path.MoveTo(toX+CircleOuterRadius, toY)
+		path.Arc(toX, toY, CircleOuterRadius, 0, 2*math.Pi, vector.Clockwise)
+		path.MoveTo(toX+inner, toY)
+		path.Arc(toX, toY, inner, 0, 2*math.Pi, vector.CounterClockwise)
+	} else {
+		// unit vector along the arrow (dx, dy) and its normal (nx, ny)
+		length := float32(math.Hypot(float64(toX-fromX), float64(toY-fromY)))
+		dx, dy := (toX-fromX)/length, (toY-fromY)/length
+		nx, ny := -dy, dx
+
+		startX, startY := fromX+dx*ArrowStartOffset, fromY+dy*ArrowStartOffset
+		headX, headY := toX-dx*ArrowHeadLength, toY-dy*ArrowHeadLength
+
+		path.MoveTo(startX+nx*ArrowShaftWidth/2, startY+ny*ArrowShaftWidth/2)
+		path.LineTo(headX+nx*ArrowShaftWidth/2, headY+ny*ArrowShaftWidth/2)
+		path.LineTo(headX+nx*ArrowHeadWidth/2, headY+ny*ArrowHeadWidth/2)
+		path.LineTo(toX, toY)
+		path.LineTo(headX-nx*ArrowHeadWidth/2, headY-ny*ArrowHeadWidth/2)
+		path.LineTo(headX-nx*ArrowShaftWidth/2, headY-ny*ArrowShaftWidth/2)
+		path.LineTo(startX-nx*ArrowShaftWidth/2, startY-ny*ArrowShaftWidth/2)
+	}
+
+	FillPath(g.annotationImage, &path, annotationColors[a.colorCode])
+}
+
+// FillPath fills path on dst with a solid color. Overlapping sub-paths cut holes in each other (even-odd rule), so a
+// ring is an outer circle with an inner circle inside it.
+func FillPath(dst *ebiten.Image, path *vector.Path, clr color.Color) {
+	// vertex colors are straight (not premultiplied) alpha
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	for i := range vs {
+		vs[i].SrcX = 1
+		vs[i].SrcY = 1
+		vs[i].ColorR = float32(c.R) / 0xff
+		vs[i].ColorG = float32(c.G) / 0xff
+		vs[i].ColorB = float32(c.B) / 0xff
+		vs[i].ColorA = float32(c.A) / 0xff
+	}
+	dst.DrawTriangles(vs, is, emptySubImage, &ebiten.DrawTrianglesOptions{FillRule: ebiten.EvenOdd})
+}
+
+// BoardSquareCenter returns the center of a square in board image coordinates. Rotation of the board is handled
+// when the board layers are drawn to the screen, so this doesn't depend on which side is facing the player.
+func BoardSquareCenter(row, col int) (float32, float32) {
+	return float32(col*TileSize + TileSize/2), float32(row*TileSize + TileSize/2)
+}
+
+// AnnotationComment formats the annotations as a PGN comment using the %csl (circled squares) and %cal (arrows)
+// commands, ex. "[%csl Gd4][%cal Ge2e4,Rg8f6]". Returns an empty string if nothing is drawn.
+func (g *Game) AnnotationComment() string {
+	var circles, arrows []string
+	for _, a := range g.annotations {
+		from := SquareName(a.fromRow, a.fromCol)
+		if a.fromRow == a.toRow && a.fromCol == a.toCol {
+			circles = append(circles, string(a.colorCode)+from)
+		} else {
+			arrows = append(arrows, string(a.colorCode)+from+SquareName(a.toRow, a.toCol))
+		}
+	}
+	sort.Strings(circles)
+	sort.Strings(arrows)
+
+	comment := ""
+	if len(circles) > 0 {
+		comment += "[%csl " + strings.Join(circles, ",") + "]"
+	}
+	if len(arrows) > 0 {
+		comment += "[%cal " + strings.Join(arrows, ",") + "]"
+	}
+	return comment
+}
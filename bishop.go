@@ -7,9 +7,9 @@ type Bishop struct {
 }
 
 // Moves returns a slice of all valid moves for given Piece. Each valid move in the slice is stored
-// in an array with a length of two-- Row and Col.
-func (p *Bishop) Moves(g Game) [][2]int {
-	moves := make([][2]int, 0)
+// as a Move, with the flags and captured piece filled in.
+func (p *Bishop) Moves(g Game) []Move {
+	moves := make([]Move, 0)
 
 	//The following are for loops in the diagonals
 	col := p.col
@@ -22,14 +22,14 @@ func (p *Bishop) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -46,14 +46,14 @@ func (p *Bishop) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -71,14 +71,14 @@ func (p *Bishop) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -96,14 +96,14 @@ func (p *Bishop) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -0,0 +1,79 @@
+package main
+
+// The position is kept two ways: g.pieces lists the pieces in play, and g.board is an 8x8 mailbox with the piece on
+// each square (or nil) for quick lookups. Taken pieces move from g.pieces to g.captured. Every change to where a
+// piece stands goes through the functions below so the two stay in step. Any number of pieces is allowed, so
+// positions from the board editor don't need a full set.
+
+// PieceOn returns the piece on row, col, or nil if the square is empty or off the board
+func (g *Game) PieceOn(row, col int) ChessPiece {
+	if !IsInBounds(row, col) {
+		return nil
+	}
+	return g.board[row][col]
+}
+
+// PieceIndex returns the index of piece in g.pieces, or -1 if it isn't in play
+func (g *Game) PieceIndex(piece ChessPiece) int {
+	for i, p := range g.pieces {
+		if p == piece {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetPieces replaces every piece on the board with pieces, each on its own row and col, and empties g.captured
+func (g *Game) SetPieces(pieces []ChessPiece) {
+	g.pieces = pieces
+	g.captured = nil
+	g.board = [8][8]ChessPiece{}
+	for _, piece := range pieces {
+		g.board[piece.Row()][piece.Col()] = piece
+	}
+}
+
+// MovePiece moves piece to row, col. The square must be empty, so anything standing there should be captured first.
+func (g *Game) MovePiece(piece ChessPiece, row, col int) {
+	if g.board[piece.Row()][piece.Col()] == piece {
+		g.board[piece.Row()][piece.Col()] = nil
+	}
+	piece.SetRow(row)
+	piece.SetCol(col)
+	g.board[row][col] = piece
+}
+
+// CapturePiece takes g.pieces[index] off the board and adds it to the end of g.captured. The piece keeps its row and
+// col, so RestorePiece can put it back. Pieces after index in g.pieces move down one.
+func (g *Game) CapturePiece(index int) {
+	piece := g.pieces[index]
+	g.pieces = append(g.pieces[:index], g.pieces[index+1:]...)
+	g.captured = append(g.captured, piece)
+	if g.board[piece.Row()][piece.Col()] == piece {
+		g.board[piece.Row()][piece.Col()] = nil
+	}
+}
+
+// RestorePiece undoes the last CapturePiece, putting the piece back on its square at index in g.pieces
+func (g *Game) RestorePiece(index int) {
+	piece := g.captured[len(g.captured)-1]
+	g.captured = g.captured[:len(g.captured)-1]
+	g.pieces = append(g.pieces[:index], append([]ChessPiece{piece}, g.pieces[index:]...)...)
+	g.board[piece.Row()][piece.Col()] = piece
+}
+
+// AddPiece puts piece on the board on its row and col, replacing any piece already there
+func (g *Game) AddPiece(piece ChessPiece) {
+	if i := g.PieceIndex(g.board[piece.Row()][piece.Col()]); i != -1 {
+		g.RemovePiece(i)
+	}
+	g.pieces = append(g.pieces, piece)
+	g.board[piece.Row()][piece.Col()] = piece
+}
+
+// RemovePiece takes g.pieces[index] off the board without capturing it
+func (g *Game) RemovePiece(index int) {
+	piece := g.pieces[index]
+	g.pieces = append(g.pieces[:index], g.pieces[index+1:]...)
+	g.board[piece.Row()][piece.Col()] = nil
+}
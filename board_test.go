@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestCaptureAndRestorePiece(t *testing.T) {
+	g := loadTestGame(t, "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1")
+	pawn := g.PieceOn(3, 3)
+	index := g.PieceIndex(pawn)
+	if pawn == nil || index == -1 {
+		t.Fatal("no pawn on d5")
+	}
+	count := len(g.pieces)
+
+	g.CapturePiece(index)
+	if len(g.pieces) != count-1 {
+		t.Errorf("%d pieces after a capture, want %d", len(g.pieces), count-1)
+	}
+	if g.PieceOn(3, 3) != nil || g.PieceIndex(pawn) != -1 {
+		t.Error("captured pawn is still in play")
+	}
+	if len(g.captured) != 1 || g.captured[0] != pawn {
+		t.Errorf("captured = %v, want the pawn", g.captured)
+	}
+
+	g.RestorePiece(index)
+	if len(g.pieces) != count {
+		t.Errorf("%d pieces after a restore, want %d", len(g.pieces), count)
+	}
+	if g.PieceOn(3, 3) != pawn || g.PieceIndex(pawn) != index {
+		t.Errorf("restored pawn is at index %d, want it back on d5 at index %d", g.PieceIndex(pawn), index)
+	}
+	if len(g.captured) != 0 {
+		t.Errorf("captured = %v, want it empty", g.captured)
+	}
+}
+
+func TestAddAndRemovePiece(t *testing.T) {
+	g := loadTestGame(t, "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1")
+	pawn := g.PieceOn(3, 3)
+	count := len(g.pieces)
+
+	// a piece added on an occupied square replaces the piece there, which isn't captured
+	queen := NewPiece('Q', 3, 3, true)
+	g.AddPiece(queen)
+	if len(g.pieces) != count {
+		t.Errorf("%d pieces after replacing the pawn, want %d", len(g.pieces), count)
+	}
+	if g.PieceOn(3, 3) != queen || g.PieceIndex(queen) == -1 || g.PieceIndex(pawn) != -1 {
+		t.Error("queen didn't replace the pawn on d5")
+	}
+	if len(g.captured) != 0 {
+		t.Errorf("captured = %v, want it empty", g.captured)
+	}
+
+	g.RemovePiece(g.PieceIndex(queen))
+	if len(g.pieces) != count-1 || g.PieceOn(3, 3) != nil || g.PieceIndex(queen) != -1 {
+		t.Error("queen is still on d5 after being removed")
+	}
+	if len(g.captured) != 0 {
+		t.Errorf("captured = %v, want it empty", g.captured)
+	}
+}
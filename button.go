@@ -1,25 +1,119 @@
 package main
 
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/colornames"
+)
+
+// Button is a clickable rectangle with a label. onClick runs when the button is clicked, or pressed with Enter or
+// Space while focused. primary buttons use the primary button images, the rest use the info images.
 type Button struct {
-	text     string
-	fontSize int
-	x        int
-	y        int
+	widgetBase
+	text    string
+	primary bool
+	onClick func()
 }
 
+// BtnWidth and BtnHeight are the size of a button at the design resolution, matching the button images
 const (
 	BtnHeight = 80
 	BtnWidth  = 180
 )
 
-func (b *Button) TextX() int {
-	return b.x + BtnWidth/2 - ((len(b.text) * b.fontSize) / 2)
+func NewButton(text string, onClick func()) *Button {
+	return &Button{text: text, onClick: onClick}
+}
+
+func (b *Button) Release(ui *UI, x, y int, inside bool) {
+	if inside {
+		b.click()
+	}
+}
+
+func (b *Button) Key(ui *UI) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		b.click()
+	}
+}
+
+func (b *Button) click() {
+	if b.onClick != nil && b.Enabled() {
+		b.onClick()
+	}
+}
+
+// Draw stretches the button image over the button's rect and draws the centered text on top. Disabled buttons
+// are faded out.
+func (b *Button) Draw(dst *ebiten.Image, ui *UI) {
+	b.drawLabelled(dst, ui, b.text)
+}
+
+func (b *Button) drawLabelled(dst *ebiten.Image, ui *UI, label string) {
+	img, hoverImg := ui.theme.Info, ui.theme.InfoHover
+	if b.primary {
+		img, hoverImg = ui.theme.Primary, ui.theme.PrimaryHover
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	textColor := colornames.Whitesmoke
+	if ui.Highlighted(b) {
+		img = hoverImg
+		textColor = colornames.Gray
+	} else if !b.Enabled() {
+		op.ColorM.Translate(-.1, -.1, -.1, -.5)
+		textColor = colornames.Gray
+	}
+	DrawImageInRect(dst, img, b.rect, op)
+
+	x, y := textPosIn(ui.theme.Font, label, b.rect)
+	text.Draw(dst, label, ui.theme.Font, x, y, textColor)
+}
+
+// Toggle is a button that switches a setting on and off, labelled with the setting's name and its state,
+// ex. "AutoFlip On". onChange is called with the new state.
+type Toggle struct {
+	Button
+	on       bool
+	onChange func(on bool)
+}
+
+func NewToggle(label string, on bool, onChange func(on bool)) *Toggle {
+	t := &Toggle{on: on, onChange: onChange}
+	t.text = label
+	t.onClick = func() {
+		t.on = !t.on
+		if t.onChange != nil {
+			t.onChange(t.on)
+		}
+	}
+	return t
+}
+
+func (t *Toggle) On() bool {
+	return t.on
+}
+
+// SetOn switches the toggle without calling onChange
+func (t *Toggle) SetOn(on bool) {
+	t.on = on
 }
 
-func (b *Button) TextY() int {
-	return b.y + BtnHeight/2 + b.fontSize/2
+func (t *Toggle) Draw(dst *ebiten.Image, ui *UI) {
+	if t.on {
+		t.drawLabelled(dst, ui, t.text+" On")
+	} else {
+		t.drawLabelled(dst, ui, t.text+" Off")
+	}
 }
 
-func (b *Button) PosInBounds(x, y int) bool {
-	return x >= b.x && x <= b.x+BtnWidth && y >= b.y && y <= b.y+BtnHeight
+// DrawImageInRect stretches img over rect. Any color changes in op are kept, but its GeoM is replaced.
+func DrawImageInRect(dst *ebiten.Image, img *ebiten.Image, rect Rect, op *ebiten.DrawImageOptions) {
+	w, h := img.Size()
+	op.GeoM.Reset()
+	op.GeoM.Scale(rect.W/float64(w), rect.H/float64(h))
+	op.GeoM.Translate(rect.X, rect.Y)
+	op.Filter = Filter
+	dst.DrawImage(img, op)
 }
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CopyToClipboard puts s on the system clipboard. Ebitengine has no clipboard support, so this runs the platform's
+// clipboard tool: clip on Windows, pbcopy on macOS, and xclip or xsel elsewhere.
+func CopyToClipboard(s string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("clip")
+	case "darwin":
+		cmd = exec.Command("pbcopy")
+	default:
+		if _, err := exec.LookPath("xclip"); err == nil {
+			cmd = exec.Command("xclip", "-selection", "clipboard")
+		} else {
+			cmd = exec.Command("xsel", "--clipboard", "--input")
+		}
+	}
+	cmd.Stdin = strings.NewReader(s)
+	return cmd.Run()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/colornames"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// Dialog sizes at the design resolution
+const (
+	DialogWidth      = 640
+	DialogPadding    = 32
+	DialogLineHeight = 26
+)
+
+// Dialog is a modal box with a title, a message and a row of buttons, opened with UI.ShowDialog. While it is open,
+// the rest of the screen is dimmed and doesn't receive input. The message is wrapped to fit, and may contain
+// newlines to start new lines. onCancel is called when Escape dismisses the dialog, and Escape does nothing when
+// it is nil. Buttons don't close the dialog by themselves, so their onClick should call UI.CloseDialog.
+type Dialog struct {
+	title         string
+	message       string
+	buttons       []*Button
+	onCancel      func()
+	rect          Rect
+	lines         []string
+	previousFocus Widget
+}
+
+func NewDialog(title, message string, buttons ...*Button) *Dialog {
+	return &Dialog{title: title, message: message, buttons: buttons}
+}
+
+func (d *Dialog) widgets() []Widget {
+	widgets := make([]Widget, len(d.buttons))
+	for i, b := range d.buttons {
+		widgets[i] = b
+	}
+	return widgets
+}
+
+// layout centers the dialog on the screen, sized to fit the wrapped message, with its buttons in a row along the
+// bottom
+func (d *Dialog) layout(ui *UI) {
+	scale := ui.theme.Scale
+	width := math.Min(DialogWidth*scale, ui.screen.W*0.95)
+	padding := DialogPadding * scale
+
+	// the UI font is monospaced, so the wrap width is a number of characters
+	charWidth := float64(text.BoundString(ui.theme.Font, "M").Dx())
+	if charWidth <= 0 {
+		charWidth = 1
+	}
+	d.lines = d.lines[:0]
+	for _, paragraph := range strings.Split(d.message, "\n") {
+		wrapped := wrapText(paragraph, int((width-2*padding)/charWidth))
+		if len(wrapped) == 0 {
+			wrapped = []string{""}
+		}
+		d.lines = append(d.lines, wrapped...)
+	}
+
+	lineH := DialogLineHeight * scale
+	btnW, btnH, gap := BtnWidth*scale, BtnHeight*scale, BtnGap*scale
+	height := padding + lineH*1.5 + float64(len(d.lines))*lineH + padding + btnH + padding
+	d.rect = Rect{X: (ui.screen.W - width) / 2, Y: (ui.screen.H - height) / 2, W: width, H: height}
+
+	// buttons shrink to fit when there are too many for the dialog's width
+	n := float64(len(d.buttons))
+	btnW = math.Min(btnW, (width-2*padding-(n-1)*gap)/n)
+	rowX := d.rect.X + (width-(n*btnW+(n-1)*gap))/2
+	for i, b := range d.buttons {
+		b.SetBounds(Rect{X: rowX + float64(i)*(btnW+gap), Y: d.rect.Y + height - padding - btnH, W: btnW, H: btnH})
+	}
+}
+
+func (d *Dialog) Draw(dst *ebiten.Image, ui *UI) {
+	// dim everything behind the dialog
+	ebitenutil.DrawRect(dst, ui.screen.X, ui.screen.Y, ui.screen.W, ui.screen.H, color.NRGBA{A: 0x90})
+	ui.drawBox(dst, d.rect, true)
+
+	scale := ui.theme.Scale
+	padding := DialogPadding * scale
+	lineH := DialogLineHeight * scale
+	titleRect := Rect{X: d.rect.X, Y: d.rect.Y + padding, W: d.rect.W, H: lineH}
+	x, y := textPosIn(ui.theme.Font, d.title, titleRect)
+	text.Draw(dst, d.title, ui.theme.Font, x, y, colornames.White)
+
+	lineY := titleRect.Y + lineH*1.5
+	for _, line := range d.lines {
+		x, y := textPosIn(ui.theme.Font, line, Rect{X: d.rect.X, Y: lineY, W: d.rect.W, H: lineH})
+		text.Draw(dst, line, ui.theme.Font, x, y, colornames.Whitesmoke)
+		lineY += lineH
+	}
+
+	for _, b := range d.buttons {
+		b.Draw(dst, ui)
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/colornames"
+	"image/color"
+	"log"
+	"math"
+)
+
+const (
+	// EditorControlCount is the number of controls in the board editor, not counting the FEN box
+	EditorControlCount = 12
+	// PalettePieceCount is the number of pieces in the board editor's palette, every kind in both colors
+	PalettePieceCount = 12
+)
+
+// paletteLetters are the FEN letters of the pieces in the palette, white's then black's
+const paletteLetters = "KQRBNPkqrbnp"
+
+// BoardEditor is the screen for setting up a position to play from (gameType 2). Pieces are dragged onto the board
+// from the palette, moved around by dragging them, and removed by dragging them off the board or right-clicking
+// them. The position being edited is the game's own pieces and state, so the controls change the Game directly.
+//
+// castling are the castling right toggles: white king side, white queen side, black king side, black queen side.
+// held is the piece being dragged, with heldImage, or nil. message explains why the position can't be played, or
+// that the FEN was copied, drawn in messageColor.
+type BoardEditor struct {
+	ui            *UI
+	controls      [EditorControlCount]Widget
+	sideToMove    *Dropdown
+	enPassant     *Dropdown
+	castling      [4]*Toggle
+	fen           *TextInput
+	paletteImages [PalettePieceCount]*ebiten.Image
+	held          ChessPiece
+	heldImage     *ebiten.Image
+	message       string
+	messageColor  color.Color
+}
+
+// NewBoardEditor creates the board editor's widgets, which edit the position in g
+func (g *Game) NewBoardEditor() *BoardEditor {
+	e := &BoardEditor{ui: NewUI(&g.theme, false)}
+
+	e.sideToMove = NewDropdown([]string{"White moves", "Black moves"}, 0, func(index int) {
+		g.whitesTurn = index == 0
+		// the en passant pawn belongs to the side that just moved
+		g.SetEnPassantFile(e.enPassant.Selected() - 1)
+		g.plyOffset = g.plyOffset/2*2 + index
+		g.EditorChanged()
+	})
+
+	files := []string{"No e.p."}
+	for col := 0; col < 8; col++ {
+		files = append(files, "e.p. on "+string(rune('a'+col)))
+	}
+	e.enPassant = NewDropdown(files, 0, func(index int) {
+		g.SetEnPassantFile(index - 1)
+		g.EditorChanged()
+	})
+
+	labels := []string{"W O-O", "W O-O-O", "B O-O", "B O-O-O"}
+	for i := range e.castling {
+		right := g.castlingRight(i)
+		e.castling[i] = NewToggle(labels[i], *right, func(on bool) {
+			*right = on
+			g.EditorChanged()
+		})
+	}
+
+	startPos := NewButton("Start Pos", func() {
+		g.LoadEditorFEN(StartingFEN)
+	})
+	clearBoard := NewButton("Clear", func() {
+		g.SetPieces(nil)
+		g.EditorChanged()
+	})
+	copyFEN := NewButton("Copy FEN", g.CopyFEN)
+	play := NewButton("Play", g.PlayEditorPosition)
+	play.primary = true
+	mainMenu := NewButton("Main Menu", func() {
+		g.gameType = -1
+	})
+	mainMenu.primary = true
+
+	e.controls = [EditorControlCount]Widget{
+		e.sideToMove, e.enPassant,
+		e.castling[0], e.castling[1],
+		e.castling[2], e.castling[3],
+		clearBoard, startPos,
+		NewButton("Flip Board", g.FlipBoard), copyFEN,
+		mainMenu, play,
+	}
+	e.fen = NewTextInput("Type a FEN", FENMaxLen, func(fen string) {
+		g.LoadEditorFEN(fen)
+	})
+	e.ui.Add(e.controls[:]...)
+	e.ui.Add(e.fen)
+
+	for i := range e.paletteImages {
+		e.paletteImages[i] = NewPiece(paletteLetters[i], 0, 0, i < PalettePieceCount/2).Image()
+	}
+	return e
+}
+
+// SetBounds places the editor's widgets where layout says
+func (e *BoardEditor) SetBounds(layout *ScreenLayout) {
+	for i, w := range e.controls {
+		w.SetBounds(layout.EditorControls[i])
+	}
+	e.fen.SetBounds(layout.EditorFEN)
+}
+
+// castlingRight is the castling right changed by the editor's castling toggle i
+func (g *Game) castlingRight(i int) *bool {
+	return [4]*bool{&g.whiteCastles[1], &g.whiteCastles[0], &g.blackCastles[1], &g.blackCastles[0]}[i]
+}
+
+// SetEnPassantFile lets the pawn on file col that the side not to move just pushed two squares be taken en passant,
+// or nobody when col is -1
+func (g *Game) SetEnPassantFile(col int) {
+	switch {
+	case col == -1:
+		g.enPassantLocation = [2]int{-1, -1}
+	case g.whitesTurn:
+		g.enPassantLocation = [2]int{3, col}
+	default:
+		g.enPassantLocation = [2]int{4, col}
+	}
+}
+
+// OpenEditor switches to the board editor with the starting position set up
+func (g *Game) OpenEditor() {
+	g.gameType = 2
+	g.boardFlipped = false
+	g.selectedPiece = -1
+	g.keyboardHeld = false
+	g.inCheck = false
+	g.lastMove = [2][2]int{{-1, -1}, {-1, -1}}
+	g.animations = g.animations[:0]
+	g.annotations = g.annotations[:0]
+	g.annotationImage.Clear()
+	g.editor.held = nil
+	g.editor.ui.Focus(nil)
+	g.editor.fen.SetText("")
+
+	// the board may not have been drawn yet if no game has been played
+	g.boardImage.Clear()
+	g.DrawBoard()
+
+	if err := g.LoadEditorFEN(StartingFEN); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// LoadEditorFEN sets up the position described by fen in the editor, and updates the controls to match it
+func (g *Game) LoadEditorFEN(fen string) error {
+	e := g.editor
+	if err := g.LoadFEN(fen); err != nil {
+		e.SetMessage("Couldn't read the FEN: "+err.Error(), colornames.Darkred)
+		return err
+	}
+
+	if g.whitesTurn {
+		e.sideToMove.Select(0)
+	} else {
+		e.sideToMove.Select(1)
+	}
+	e.enPassant.Select(0)
+	if g.enPassantLocation[0] != -1 {
+		e.enPassant.Select(g.enPassantLocation[1] + 1)
+	}
+	for i, toggle := range e.castling {
+		toggle.SetOn(*g.castlingRight(i))
+	}
+	e.fen.SetText("")
+	g.EditorChanged()
+	return nil
+}
+
+// EditorChanged is called whenever the position in the editor changes
+func (g *Game) EditorChanged() {
+	g.editor.message = ""
+	g.scheduleDraw = true
+}
+
+// SetMessage shows message below the FEN box in clr
+func (e *BoardEditor) SetMessage(message string, clr color.Color) {
+	e.message = message
+	e.messageColor = clr
+}
+
+// RemovePieceAt takes the piece on row, col off the board and returns it, or nil if the square was empty
+func (g *Game) RemovePieceAt(row, col int) ChessPiece {
+	piece := g.PieceOn(row, col)
+	if piece == nil {
+		return nil
+	}
+	g.RemovePiece(g.PieceIndex(piece))
+	g.EditorChanged()
+	return piece
+}
+
+// PlacePiece puts piece on row, col, replacing any piece already there
+func (g *Game) PlacePiece(piece ChessPiece, row, col int) {
+	piece.SetRow(row)
+	piece.SetCol(col)
+	g.AddPiece(piece)
+	g.EditorChanged()
+}
+
+// CopyFEN copies the FEN of the position to the clipboard. It is printed to the console instead when no clipboard
+// tool is installed.
+func (g *Game) CopyFEN() {
+	fen := g.FEN()
+	if err := CopyToClipboard(fen); err != nil {
+		fmt.Println(fen)
+		g.editor.SetMessage("Couldn't copy the FEN, it was printed to the console instead", colornames.Darkred)
+		return
+	}
+	g.editor.SetMessage("FEN copied", colornames.Whitesmoke)
+}
+
+// PlayEditorPosition starts a local match from the position in the editor, if it is a legal one
+func (g *Game) PlayEditorPosition() {
+	if err := g.ValidatePosition(); err != nil {
+		g.editor.SetMessage(err.Error(), colornames.Darkred)
+		return
+	}
+	g.startFEN = g.FEN()
+	if g.startFEN == StartingFEN {
+		g.startFEN = ""
+	}
+	g.colorsSwapped = false
+	g.gameType = 1
+	g.StartGame()
+}
+
+// paletteAt returns the index of the palette piece under x, y, or -1
+func (g *Game) paletteAt(x, y int) int {
+	for i, r := range g.layout.EditorPalette {
+		if r.Contains(x, y) {
+			return i
+		}
+	}
+	return -1
+}
+
+// UpdateEditor handles the mouse and keyboard in the board editor
+func (g *Game) UpdateEditor() {
+	e := g.editor
+	x, y := ebiten.CursorPosition()
+	row, col, onBoard := g.layout.SquareAt(x, y)
+	if g.BoardFlipped() {
+		row, col = 7-row, 7-col
+	}
+
+	pointerOnUI := e.ui.Update()
+	if g.gameType != 2 {
+		// Play or Main Menu was clicked
+		return
+	}
+
+	if !pointerOnUI && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if i := g.paletteAt(x, y); i != -1 {
+			e.held = NewPiece(paletteLetters[i], -1, -1, i < PalettePieceCount/2)
+		} else if onBoard {
+			e.held = g.RemovePieceAt(row, col)
+		}
+		if e.held != nil {
+			e.heldImage = e.held.Image()
+		}
+	} else if !pointerOnUI && onBoard && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.RemovePieceAt(row, col)
+	}
+
+	// a piece let go of anywhere but the board is removed
+	if e.held != nil && !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		if onBoard {
+			g.PlacePiece(e.held, row, col)
+		}
+		e.held = nil
+	}
+
+	e.ui.UpdateKeyboard()
+}
+
+// DrawEditor draws the board editor: the board with the square under a held piece highlighted, the palette, the
+// controls and the held piece on top
+func (g *Game) DrawEditor(screen *ebiten.Image) {
+	e := g.editor
+	x, y := ebiten.CursorPosition()
+
+	g.gameImage.Clear()
+	if row, col, onBoard := g.layout.SquareAt(x, y); e.held != nil && onBoard {
+		if g.BoardFlipped() {
+			row, col = 7-row, 7-col
+		}
+		ebitenutil.DrawRect(g.gameImage, float64(col*TileSize), float64(row*TileSize), TileSize, TileSize,
+			color.RGBA{R: 0xea, G: 0xdd, B: 0x23, A: 0xff})
+	}
+	if g.scheduleDraw {
+		g.DrawStaticPieces()
+		g.scheduleDraw = false
+	}
+
+	g.uiImage.Clear()
+	g.DrawCoordinates()
+	for i, r := range g.layout.EditorPalette {
+		e.ui.drawBox(g.uiImage, r, r.Contains(x, y) && !e.ui.Modal())
+		inset := r.W * 0.1
+		DrawImageInRect(g.uiImage, e.paletteImages[i], Rect{X: r.X + inset, Y: r.Y + inset, W: r.W - 2*inset, H: r.H - 2*inset}, &ebiten.DrawImageOptions{})
+	}
+
+	// the UI font is monospaced, so the message is wrapped to the width of the FEN box in characters
+	charWidth := math.Max(float64(text.BoundString(g.uiFontSmall, "M").Dx()), 1)
+	msgY := g.layout.EditorMessageY
+	for _, line := range wrapText(e.message, int(g.layout.EditorFEN.W/charWidth)) {
+		text.Draw(g.uiImage, line, g.uiFontSmall, int(g.layout.EditorFEN.X), int(msgY), e.messageColor)
+		msgY += 22 * g.layout.Scale
+	}
+	e.ui.Draw(g.uiImage)
+
+	g.movingImage.Clear()
+	if e.held != nil {
+		scale := PieceScale * g.layout.BoardScale()
+		opPiece := &ebiten.DrawImageOptions{}
+		opPiece.GeoM.Scale(scale, scale)
+		opPiece.GeoM.Translate(float64(x)-PieceImageSize*scale/2, float64(y)-PieceImageSize*scale/2)
+		opPiece.Filter = Filter
+		g.movingImage.DrawImage(e.heldImage, opPiece)
+	}
+
+	g.DrawBoardLayers(screen)
+	screen.DrawImage(g.uiImage, &ebiten.DrawImageOptions{})
+	screen.DrawImage(g.movingImage, &ebiten.DrawImageOptions{})
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// The kinds of piece, used to index the evaluation weights
+const (
+	kindPawn = iota
+	kindKnight
+	kindBishop
+	kindRook
+	kindQueen
+	kindKing
+)
+
+// PhaseMax is the game phase of the starting position, see GamePhase
+const PhaseMax = 24
+
+// phaseWeights is how much each kind of piece adds to the game phase
+var phaseWeights = [6]int{0, 1, 1, 2, 4, 0}
+
+// EvalParams are the weights of the evaluation, in centipawns. Each weight is a pair: its value in the middlegame
+// and in the endgame, which Evaluate blends by GamePhase. The piece-square tables are from white's point of view with
+// rank 8 first, like a FEN, and are mirrored for black. Passed is indexed by how far the pawn has come, from 1 on its
+// starting rank to 6 a step from promoting. Mobility is per move a piece has, ignoring pins. PawnShield is per pawn
+// on the three files around the king within two ranks in front of it, and KingOpenFile is per one of those files
+// with none of the king's own pawns.
+type EvalParams struct {
+	Material     [6][2]int
+	PST          [6][64][2]int
+	Doubled      [2]int
+	Isolated     [2]int
+	Passed       [8][2]int
+	Mobility     [6][2]int
+	BishopPair   [2]int
+	PawnShield   [2]int
+	KingOpenFile [2]int
+}
+
+// DefaultEvalParams are hand-picked starting weights, meant to be improved by tuning, see RunTuning
+func DefaultEvalParams() *EvalParams {
+	p := &EvalParams{
+		Material:     [6][2]int{{100, 120}, {320, 300}, {330, 320}, {500, 520}, {900, 940}, {0, 0}},
+		Doubled:      [2]int{-10, -20},
+		Isolated:     [2]int{-10, -15},
+		Passed:       [8][2]int{{0, 0}, {5, 10}, {10, 15}, {15, 25}, {25, 45}, {40, 70}, {60, 110}, {0, 0}},
+		Mobility:     [6][2]int{{0, 0}, {4, 4}, {5, 5}, {2, 4}, {1, 2}, {0, 0}},
+		BishopPair:   [2]int{30, 50},
+		PawnShield:   [2]int{10, 0},
+		KingOpenFile: [2]int{-15, 0},
+	}
+
+	middlegame := [6][64]int{
+		{ // pawn
+			0, 0, 0, 0, 0, 0, 0, 0,
+			50, 50, 50, 50, 50, 50, 50, 50,
+			10, 10, 20, 30, 30, 20, 10, 10,
+			5, 5, 10, 25, 25, 10, 5, 5,
+			0, 0, 0, 20, 20, 0, 0, 0,
+			5, -5, -10, 0, 0, -10, -5, 5,
+			5, 10, 10, -20, -20, 10, 10, 5,
+			0, 0, 0, 0, 0, 0, 0, 0,
+		},
+		{ // knight
+			-50, -40, -30, -30, -30, -30, -40, -50,
+			-40, -20, 0, 0, 0, 0, -20, -40,
+			-30, 0, 10, 15, 15, 10, 0, -30,
+			-30, 5, 15, 20, 20, 15, 5, -30,
+			-30, 0, 15, 20, 20, 15, 0, -30,
+			-30, 5, 10, 15, 15, 10, 5, -30,
+			-40, -20, 0, 5, 5, 0, -20, -40,
+			-50, -40, -30, -30, -30, -30, -40, -50,
+		},
+		{ // bishop
+			-20, -10, -10, -10, -10, -10, -10, -20,
+			-10, 0, 0, 0, 0, 0, 0, -10,
+			-10, 0, 5, 10, 10, 5, 0, -10,
+			-10, 5, 5, 10, 10, 5, 5, -10,
+			-10, 0, 10, 10, 10, 10, 0, -10,
+			-10, 10, 10, 10, 10, 10, 10, -10,
+			-10, 5, 0, 0, 0, 0, 5, -10,
+			-20, -10, -10, -10, -10, -10, -10, -20,
+		},
+		{ // rook
+			0, 0, 0, 0, 0, 0, 0, 0,
+			5, 10, 10, 10, 10, 10, 10, 5,
+			-5, 0, 0, 0, 0, 0, 0, -5,
+			-5, 0, 0, 0, 0, 0, 0, -5,
+			-5, 0, 0, 0, 0, 0, 0, -5,
+			-5, 0, 0, 0, 0, 0, 0, -5,
+			-5, 0, 0, 0, 0, 0, 0, -5,
+			0, 0, 0, 5, 5, 0, 0, 0,
+		},
+		{ // queen
+			-20, -10, -10, -5, -5, -10, -10, -20,
+			-10, 0, 0, 0, 0, 0, 0, -10,
+			-10, 0, 5, 5, 5, 5, 0, -10,
+			-5, 0, 5, 5, 5, 5, 0, -5,
+			0, 0, 5, 5, 5, 5, 0, -5,
+			-10, 5, 5, 5, 5, 5, 0, -10,
+			-10, 0, 5, 0, 0, 0, 0, -10,
+			-20, -10, -10, -5, -5, -10, -10, -20,
+		},
+		{ // king, tucked away behind its pawns
+			-30, -40, -40, -50, -50, -40, -40, -30,
+			-30, -40, -40, -50, -50, -40, -40, -30,
+			-30, -40, -40, -50, -50, -40, -40, -30,
+			-30, -40, -40, -50, -50, -40, -40, -30,
+			-20, -30, -30, -40, -40, -30, -30, -20,
+			-10, -20, -20, -20, -20, -20, -20, -10,
+			20, 20, 0, 0, 0, 0, 20, 20,
+			20, 30, 10, 0, 0, 10, 30, 20,
+		},
+	}
+	// the endgame tables start out the same, except pawns are worth more as they advance and the king heads for
+	// the center
+	endgame := middlegame
+	endgame[kindPawn] = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		30, 30, 30, 30, 30, 30, 30, 30,
+		20, 20, 20, 20, 20, 20, 20, 20,
+		10, 10, 10, 10, 10, 10, 10, 10,
+		5, 5, 5, 5, 5, 5, 5, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	endgame[kindKing] = [64]int{
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	}
+	for kind := range p.PST {
+		for sq := range p.PST[kind] {
+			p.PST[kind][sq] = [2]int{middlegame[kind][sq], endgame[kind][sq]}
+		}
+	}
+	return p
+}
+
+// LoadEvalParams reads weights saved by SaveEvalParams. Weights missing from the file keep their default values.
+func LoadEvalParams(path string) (*EvalParams, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := DefaultEvalParams()
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SaveEvalParams writes p to path as indented JSON, so weights can be edited by hand
+func SaveEvalParams(p *EvalParams, path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// weights lists every weight in p that tuning should change. Weights that can never apply, like the material value
+// of the king or pawns on the first and last rank, are left out.
+func (p *EvalParams) weights() []*int {
+	var weights []*int
+	pair := func(w *[2]int) {
+		weights = append(weights, &w[0], &w[1])
+	}
+	for kind := kindPawn; kind < kindKing; kind++ {
+		pair(&p.Material[kind])
+	}
+	for kind := range p.PST {
+		for sq := range p.PST[kind] {
+			if kind == kindPawn && (sq < 8 || sq >= 56) {
+				continue
+			}
+			pair(&p.PST[kind][sq])
+		}
+	}
+	pair(&p.Doubled)
+	pair(&p.Isolated)
+	for rank := 1; rank < 7; rank++ {
+		pair(&p.Passed[rank])
+	}
+	for kind := kindKnight; kind < kindKing; kind++ {
+		pair(&p.Mobility[kind])
+	}
+	pair(&p.BishopPair)
+	pair(&p.PawnShield)
+	pair(&p.KingOpenFile)
+	return weights
+}
+
+// pieceKind returns the kind of piece, ex. kindKnight
+func pieceKind(piece ChessPiece) int {
+	switch piece.Name()[6:] {
+	case "pawn":
+		return kindPawn
+	case "knight":
+		return kindKnight
+	case "bishop":
+		return kindBishop
+	case "rook":
+		return kindRook
+	case "queen":
+		return kindQueen
+	default:
+		return kindKing
+	}
+}
+
+// GamePhase is how much material is left, from PhaseMax with every knight, bishop, rook and queen on the board down
+// to 0 with only kings and pawns. Promoted pieces can't push it past PhaseMax.
+func (g *Game) GamePhase() int {
+	phase := 0
+	for _, piece := range g.pieces {
+		phase += phaseWeights[pieceKind(piece)]
+	}
+	if phase > PhaseMax {
+		return PhaseMax
+	}
+	return phase
+}
+
+// Evaluate scores the position with the weights in p, in centipawns from white's point of view. The middlegame and
+// endgame values of each weight are blended by GamePhase.
+func (g *Game) Evaluate(p *EvalParams) int {
+	var mg, eg int
+	g.evalTerms(p, func(weight *[2]int, count int) {
+		mg += weight[0] * count
+		eg += weight[1] * count
+	})
+	return blendPhase(mg, eg, g.GamePhase())
+}
+
+// blendPhase blends middlegame and endgame scores by the game phase, see GamePhase. Tuning scores positions with it
+// too, so the weights are fitted to exactly what Evaluate works out.
+func blendPhase(mg, eg, phase int) int {
+	return (mg*phase + eg*(PhaseMax-phase)) / PhaseMax
+}
+
+// evalTerms calls add for each term of the evaluation with the weight from p and how many times it applies, which
+// is negative for black. Evaluate totals the terms, and tuning records them to replay with other weights.
+func (g *Game) evalTerms(p *EvalParams, add func(weight *[2]int, count int)) {
+	// pawnFiles counts each side's pawns on each file, white first
+	var pawnFiles [2][8]int
+	var bishops [2]int
+	for _, piece := range g.pieces {
+		side, sign, sq := 0, 1, piece.Row()*8+piece.Col()
+		if !piece.White() {
+			side, sign, sq = 1, -1, (7-piece.Row())*8+piece.Col()
+		}
+		kind := pieceKind(piece)
+		add(&p.Material[kind], sign)
+		add(&p.PST[kind][sq], sign)
+		switch kind {
+		case kindPawn:
+			pawnFiles[side][piece.Col()]++
+		case kindBishop:
+			bishops[side]++
+		}
+		if kind != kindPawn && kind != kindKing {
+			add(&p.Mobility[kind], sign*len(piece.Moves(*g)))
+		}
+	}
+
+	for side, sign := range []int{1, -1} {
+		if bishops[side] >= 2 {
+			add(&p.BishopPair, sign)
+		}
+		for file := 0; file < 8; file++ {
+			if pawnFiles[side][file] > 1 {
+				add(&p.Doubled, sign*(pawnFiles[side][file]-1))
+			}
+		}
+	}
+
+	for _, piece := range g.pieces {
+		side, sign, forward := 0, 1, -1
+		if !piece.White() {
+			side, sign, forward = 1, -1, 1
+		}
+		row, col := piece.Row(), piece.Col()
+
+		switch pieceKind(piece) {
+		case kindPawn:
+			if (col == 0 || pawnFiles[side][col-1] == 0) && (col == 7 || pawnFiles[side][col+1] == 0) {
+				add(&p.Isolated, sign)
+			}
+			// passed when no pawn of the other side stands in front of it on its own or a neighbouring file
+			passed := true
+			for r := row + forward; r >= 0 && r < 8 && passed; r += forward {
+				for c := col - 1; c <= col+1; c++ {
+					if other := g.PieceOn(r, c); other != nil && IsPawn(other) && other.White() != piece.White() {
+						passed = false
+					}
+				}
+			}
+			if passed {
+				rank := 7 - row
+				if !piece.White() {
+					rank = row
+				}
+				add(&p.Passed[rank], sign)
+			}
+
+		case kindKing:
+			shield, openFiles := 0, 0
+			for c := col - 1; c <= col+1; c++ {
+				if c < 0 || c > 7 {
+					continue
+				}
+				if pawnFiles[side][c] == 0 {
+					openFiles++
+				}
+				for r := row + forward; r != row+3*forward; r += forward {
+					if other := g.PieceOn(r, c); other != nil && IsPawn(other) && other.White() == piece.White() {
+						shield++
+					}
+				}
+			}
+			add(&p.PawnShield, sign*shield)
+			add(&p.KingOpenFile, sign*openFiles)
+		}
+	}
+}
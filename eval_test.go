@@ -0,0 +1,191 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mirrorFEN swaps the colors of a position: the board is turned upside down, white pieces become black and the other
+// side is to move
+func mirrorFEN(fen string) string {
+	fields := strings.Fields(fen)
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	swapCase := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z':
+				return r - 'a' + 'A'
+			case r >= 'A' && r <= 'Z':
+				return r - 'A' + 'a'
+			}
+			return r
+		}, s)
+	}
+	fields[0] = swapCase(strings.Join(ranks, "/"))
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+	if fields[2] != "-" {
+		castling := swapCase(fields[2])
+		// white's rights are written first
+		upper := strings.IndexFunc(castling, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+		if upper > 0 {
+			castling = castling[upper:] + castling[:upper]
+		}
+		fields[2] = castling
+	}
+	if fields[3] != "-" {
+		fields[3] = fields[3][:1] + string('9'-fields[3][1]+'0')
+	}
+	return strings.Join(fields, " ")
+}
+
+func TestEvaluate(t *testing.T) {
+	params := DefaultEvalParams()
+	tests := []struct {
+		name string
+		fen  string
+		want int // the sign of the score: 1 if white is better, -1 if black is, 0 for an even score
+	}{
+		{"start", StartingFEN, 0},
+		{"symmetrical", "r1bqkb1r/pppp1ppp/2n2n2/4p3/4P3/2N2N2/PPPP1PPP/R1BQKB1R w KQkq - 4 4", 0},
+		{"white a queen up", "rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 1},
+		{"black a rook up", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/1NBQKBNR w Kkq - 0 1", -1},
+		{"passed pawn", "4k3/8/8/1P6/8/8/8/4K3 w - - 0 1", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			score := g.Evaluate(params)
+			switch {
+			case tt.want == 0 && score != 0,
+				tt.want == 1 && score <= 0,
+				tt.want == -1 && score >= 0:
+				t.Errorf("Evaluate() = %d, want a score with sign %d", score, tt.want)
+			}
+
+			// swapping the colors must negate the score exactly
+			mirrored := loadTestGame(t, mirrorFEN(tt.fen))
+			if got := mirrored.Evaluate(params); got != -score {
+				t.Errorf("Evaluate() of the mirrored position %q = %d, want %d", mirrorFEN(tt.fen), got, -score)
+			}
+		})
+	}
+}
+
+// queenOdds is the starting position without black's queen
+const queenOdds = "rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+func TestRunTuning(t *testing.T) {
+	// White usually wins at queen odds and the knight shuffles are drawn, so there are weights that fit the results
+	// better than the defaults, without any weight being pushed on forever
+	tests := []struct {
+		name    string
+		file    string
+		dataset string
+	}{
+		{
+			name: "epd",
+			file: "positions.epd",
+			dataset: `rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - c9 "1-0";
+rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - c9 "1-0";
+rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - [0.5]
+rnbqkbnr/pppppppp/8/8/8/5N2/PPPPPPPP/RNBQKB1R b KQkq - c9 "1/2-1/2";
+rnbqkbnr/pppppppp/8/8/8/5N2/PPPPPPPP/RNBQKB1R b KQkq - [0.5]
+rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - [1/2-1/2]
+`,
+		},
+		{
+			name: "pgn",
+			file: "games.pgn",
+			dataset: `[Event "Queen odds"]
+[SetUp "1"]
+[FEN "` + queenOdds + `"]
+[Result "1-0"]
+
+1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 Nf6 4. Ng1 Ng8 5. Nf3 Nf6 1-0
+
+[Event "Queen odds"]
+[SetUp "1"]
+[FEN "` + queenOdds + `"]
+[Result "1/2-1/2"]
+
+1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 Nf6 4. Ng1 Ng8 5. Nf3 Nf6 1/2-1/2
+
+[Event "Shuffle"]
+[Result "1/2-1/2"]
+
+1. Nf3 Nf6 2. Ng1 Ng8 {back home} 3. Nf3 (3. e4 e5) Nf6 4. Ng1 Ng8
+5. Nf3 $1 Nf6 6. Ng1 1/2-1/2
+
+[Event "Unfinished"]
+[Result "*"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O *
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dataset := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(dataset, []byte(tt.dataset), 0644); err != nil {
+				t.Fatal(err)
+			}
+			out := filepath.Join(dir, "evalparams.json")
+
+			params := DefaultEvalParams()
+			if err := RunTuning(dataset, params, out); err != nil {
+				t.Fatalf("RunTuning: %v", err)
+			}
+			if *params == *DefaultEvalParams() {
+				t.Errorf("tuning didn't change any weights")
+			}
+			saved, err := LoadEvalParams(out)
+			if err != nil {
+				t.Fatalf("LoadEvalParams: %v", err)
+			}
+			if *saved != *params {
+				t.Errorf("the saved weights differ from the tuned ones")
+			}
+
+			// tuning can't break the symmetry of the evaluation
+			if score := loadTestGame(t, StartingFEN).Evaluate(params); score != 0 {
+				t.Errorf("Evaluate() of the starting position = %d after tuning, want 0", score)
+			}
+		})
+	}
+}
+
+func TestRunTuningErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		dataset string
+		want    string // part of the error message
+	}{
+		{"no result", "positions.epd", StartingFEN + "\n", "no game result"},
+		{"bad fen", "positions.epd", "rnbqkbnr/pppppppp/8/8 w KQkq - [0.5]\n", "8 ranks"},
+		{"no positions", "games.pgn", "[Result \"1-0\"]\n\n1. e4 e5 1-0\n", "no positions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dataset := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(dataset, []byte(tt.dataset), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := RunTuning(dataset, DefaultEvalParams(), filepath.Join(dir, "evalparams.json"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RunTuning = %v, want an error about %q", err, tt.want)
+			}
+		})
+	}
+}
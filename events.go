@@ -0,0 +1,27 @@
+package main
+
+// GameEvent is something that happened in the rules of the game. The rules code emits events without knowing who
+// is listening, so things like sound effects don't need to be wired into MakeMoveIfLegal.
+type GameEvent int
+
+const (
+	EventGameStart GameEvent = iota
+	EventMove
+	EventCapture
+	EventCastle
+	EventCheck
+	EventPromotion
+	EventIllegalMove
+	EventGameEnd
+)
+
+// OnGameEvent registers handler to be called with every GameEvent, in the order handlers were added
+func (g *Game) OnGameEvent(handler func(GameEvent)) {
+	g.eventHandlers = append(g.eventHandlers, handler)
+}
+
+func (g *Game) emit(event GameEvent) {
+	for _, handler := range g.eventHandlers {
+		handler(event)
+	}
+}
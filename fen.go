@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StartingFEN is the usual starting position in Forsyth-Edwards Notation
+const StartingFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// FENMaxLen is longer than any FEN, which is at most 92 characters
+const FENMaxLen = 100
+
+// FEN writes the current position in Forsyth-Edwards Notation, ex. the StartingFEN before the first move
+func (g *Game) FEN() string {
+	var b strings.Builder
+	for row := 0; row < 8; row++ {
+		empty := 0
+		for col := 0; col < 8; col++ {
+			piece := g.PieceOn(row, col)
+			if piece == nil {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			b.WriteByte(PieceLetter(piece))
+		}
+		if empty > 0 {
+			b.WriteString(strconv.Itoa(empty))
+		}
+		if row < 7 {
+			b.WriteByte('/')
+		}
+	}
+
+	if g.whitesTurn {
+		b.WriteString(" w ")
+	} else {
+		b.WriteString(" b ")
+	}
+
+	// castles[1] is the king side
+	castling := ""
+	for i, right := range []bool{g.whiteCastles[1], g.whiteCastles[0], g.blackCastles[1], g.blackCastles[0]} {
+		if right {
+			castling += string("KQkq"[i])
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	b.WriteString(castling + " ")
+
+	// FEN names the square behind the pawn that can be taken en passant, where the taking pawn ends up
+	switch g.enPassantLocation[0] {
+	case 3:
+		b.WriteString(SquareName(2, g.enPassantLocation[1]))
+	case 4:
+		b.WriteString(SquareName(5, g.enPassantLocation[1]))
+	default:
+		b.WriteString("-")
+	}
+
+	fmt.Fprintf(&b, " %d %d", g.halfmoveClock, (g.plyOffset+g.moveNum)/2+1)
+	return b.String()
+}
+
+// LoadFEN sets up the position described by fen: the pieces, side to move, castling rights, en passant square and
+// move counters. The halfmove clock and fullmove number may be left off. Nothing is changed if fen can't be read.
+// LoadFEN only checks that fen is well formed, see ValidatePosition for whether the position makes sense.
+func (g *Game) LoadFEN(fen string) error {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 || len(fields) > 6 {
+		return errors.New("a FEN needs the pieces, side to move, castling rights and en passant square")
+	}
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return fmt.Errorf("a FEN has 8 ranks, not %d", len(ranks))
+	}
+	pieces := make([]ChessPiece, 0, 32)
+	for row, rank := range ranks {
+		col := 0
+		for i := 0; i < len(rank); i++ {
+			c := rank[i]
+			if c >= '1' && c <= '8' {
+				col += int(c - '0')
+				continue
+			}
+			piece := NewPiece(c, row, col, c >= 'A' && c <= 'Z')
+			if piece == nil {
+				return fmt.Errorf("%q is not a piece", c)
+			}
+			if col > 7 {
+				return fmt.Errorf("rank %d has more than 8 squares", 8-row)
+			}
+			pieces = append(pieces, piece)
+			col++
+		}
+		if col != 8 {
+			return fmt.Errorf("rank %d doesn't have 8 squares", 8-row)
+		}
+	}
+
+	if fields[1] != "w" && fields[1] != "b" {
+		return fmt.Errorf("%q is not a side to move, use w or b", fields[1])
+	}
+	whitesTurn := fields[1] == "w"
+
+	var whiteCastles, blackCastles [2]bool
+	if fields[2] != "-" {
+		for _, c := range fields[2] {
+			switch c {
+			case 'K':
+				whiteCastles[1] = true
+			case 'Q':
+				whiteCastles[0] = true
+			case 'k':
+				blackCastles[1] = true
+			case 'q':
+				blackCastles[0] = true
+			default:
+				return fmt.Errorf("%q is not a castling right", c)
+			}
+		}
+	}
+
+	enPassant := [2]int{-1, -1}
+	if fields[3] != "-" {
+		row, col, err := ParseSquare(fields[3])
+		if err != nil {
+			return err
+		}
+		// the pawn that can be taken is on the square in front of the target, from its own side
+		switch {
+		case row == 2 && whitesTurn:
+			enPassant = [2]int{3, col}
+		case row == 5 && !whitesTurn:
+			enPassant = [2]int{4, col}
+		default:
+			return fmt.Errorf("%s can't be the en passant square with %s to move", fields[3], SideName(whitesTurn))
+		}
+	}
+
+	halfmoveClock, fullmove := 0, 1
+	var err error
+	if len(fields) > 4 {
+		if halfmoveClock, err = strconv.Atoi(fields[4]); err != nil || halfmoveClock < 0 {
+			return fmt.Errorf("%q is not a halfmove clock", fields[4])
+		}
+	}
+	if len(fields) > 5 {
+		if fullmove, err = strconv.Atoi(fields[5]); err != nil || fullmove < 1 {
+			return fmt.Errorf("%q is not a move number", fields[5])
+		}
+	}
+
+	g.SetPieces(pieces)
+	g.whitesTurn = whitesTurn
+	g.whiteCastles = whiteCastles
+	g.blackCastles = blackCastles
+	g.enPassantLocation = enPassant
+	g.halfmoveClock = halfmoveClock
+	g.plyOffset = 2 * (fullmove - 1)
+	if !whitesTurn {
+		g.plyOffset++
+	}
+	g.moveNum = 0
+	g.scheduleDraw = true
+	return nil
+}
+
+// ValidatePosition reports what is wrong with the position, if anything, for a game to start from it. Each side
+// needs exactly one king, pawns can't stand on the first or last rank, and the side that just moved can't have left
+// its king in check. Castling rights need the king and rook on their starting squares, and an en passant square
+// needs a pawn that could have just moved two squares past it.
+func (g *Game) ValidatePosition() error {
+	kings := map[bool]int{}
+	for _, piece := range g.pieces {
+		if IsKing(piece) {
+			kings[piece.White()]++
+		}
+		if IsPawn(piece) && (piece.Row() == 0 || piece.Row() == 7) {
+			return errors.New("pawns can't be on the first or last rank")
+		}
+	}
+	for _, white := range []bool{true, false} {
+		if kings[white] != 1 {
+			return fmt.Errorf("%s needs exactly one king", SideName(white))
+		}
+	}
+
+	if g.KingInCheck(!g.whitesTurn) {
+		return fmt.Errorf("%s is in check, but it's %s's move", SideName(!g.whitesTurn), SideName(g.whitesTurn))
+	}
+
+	// pieceIs reports whether the piece on row, col is a white (or black) king or rook
+	pieceIs := func(row, col int, white bool, isKind func(ChessPiece) bool) bool {
+		piece := g.PieceOn(row, col)
+		return piece != nil && piece.White() == white && isKind(piece)
+	}
+	for _, white := range []bool{true, false} {
+		castles, row := g.whiteCastles, 7
+		if !white {
+			castles, row = g.blackCastles, 0
+		}
+		for side, rookCol := range []int{0, 7} {
+			if castles[side] && (!pieceIs(row, 4, white, IsKing) || !pieceIs(row, rookCol, white, IsRook)) {
+				return fmt.Errorf("%s can't castle %s without the king on %s and the rook on %s", SideName(white),
+					[]string{"queen side", "king side"}[side], SquareName(row, 4), SquareName(row, rookCol))
+			}
+		}
+	}
+
+	if row, col := g.enPassantLocation[0], g.enPassantLocation[1]; row != -1 {
+		// the pawn moved from behind the en passant square, so both squares it passed over are empty
+		dir := 1
+		if row == 4 {
+			dir = -1
+		}
+		pawn := g.PieceOn(row, col)
+		if pawn == nil || !IsPawn(pawn) || pawn.White() == g.whitesTurn ||
+			g.PieceOn(row-dir, col) != nil || g.PieceOn(row-2*dir, col) != nil {
+			return fmt.Errorf("no %s pawn can be taken en passant on %s", SideName(!g.whitesTurn), SquareName(row-dir, col))
+		}
+	}
+	return nil
+}
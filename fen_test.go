@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFENRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want string // "" when the FEN should come back unchanged
+	}{
+		{"start", StartingFEN, ""},
+		{"after e4", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", ""},
+		{"en passant for white", "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", ""},
+		{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", ""},
+		{"some castling rights", "r3k2r/8/8/8/8/8/8/R3K2R b Kq - 12 40", ""},
+		{"endgame", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", ""},
+		{"promotion race", "n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1", ""},
+		{"counters left off", "4k3/8/8/8/8/8/8/4K3 w - -", "4k3/8/8/8/8/8/8/4K3 w - - 0 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Game{}
+			if err := g.LoadFEN(tt.fen); err != nil {
+				t.Fatalf("LoadFEN(%q): %v", tt.fen, err)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.fen
+			}
+			if got := g.FEN(); got != want {
+				t.Errorf("FEN() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadFENErrors(t *testing.T) {
+	tests := []struct {
+		fen  string
+		want string // part of the error message
+	}{
+		{"8/8/8/8 w - -", "8 ranks"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq -", "8 ranks"},
+		{"rnbqkbnr/ppppXppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", "not a piece"},
+		{"rnbqkbnr/pppppppp/44p/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", "more than 8 squares"},
+		{"rnbqkbnr/ppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", "doesn't have 8 squares"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq -", "side to move"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQxq -", "castling right"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e3", "en passant"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - x 1", "halfmove clock"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0", "move number"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w", "needs the pieces"},
+	}
+	for _, tt := range tests {
+		g := &Game{}
+		err := g.LoadFEN(tt.fen)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadFEN(%q) = %v, want an error about %q", tt.fen, err, tt.want)
+		}
+	}
+}
+
+func TestValidatePosition(t *testing.T) {
+	tests := []struct {
+		fen  string
+		want string // part of the error message, or empty if the position is fine
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", ""},
+		{"rnbqkbnr/pppp1ppp/8/8/3pP3/8/PPP2PPP/RNBQKBNR b KQkq e3 0 3", ""},
+		{"4k3/8/8/8/8/8/8/8 w - - 0 1", "White needs exactly one king"},
+		{"4k3/8/8/8/8/8/8/3KK3 w - - 0 1", "White needs exactly one king"},
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", ""},
+		{"3kk3/8/8/8/8/8/8/4K3 w - - 0 1", "Black needs exactly one king"},
+		{"4k3/8/8/8/8/8/8/P3K3 w - - 0 1", "first or last rank"},
+		{"p3k3/8/8/8/8/8/8/4K3 w - - 0 1", "first or last rank"},
+		{"4k3/8/8/8/8/8/8/4K2r w - - 0 1", ""},
+		{"4k3/8/8/8/8/8/8/4K2r b - - 0 1", "White is in check, but it's Black's move"},
+		{"4k2R/8/8/8/8/8/8/4K3 w - - 0 1", "Black is in check, but it's White's move"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", ""},
+		{"r3k2r/8/8/8/8/8/8/R4K1R w K - 0 1", "White can't castle king side"},
+		{"r3k2r/8/8/8/8/8/8/1R2K2R w Q - 0 1", "White can't castle queen side"},
+		{"r3k1r1/8/8/8/8/8/8/R3K2R w k - 0 1", "Black can't castle king side"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w Kq - 0 1", ""},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq e3 0 1", "no White pawn can be taken en passant on e3"},
+		{"rnbqkbnr/pppppppp/8/8/4p3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", "no White pawn can be taken en passant on e3"},
+		{"rnbqkbnr/pppp1ppp/8/4p3/8/8/PPPPPPPP/RNBQKBNR w KQkq e6 0 2", ""},
+		{"rnbqkbnr/pppp1ppp/4n3/4p3/8/8/PPPPPPPP/RNBQKBN1 w Qkq e6 0 2", "no Black pawn can be taken en passant on e6"},
+	}
+	for _, tt := range tests {
+		g := &Game{}
+		if err := g.LoadFEN(tt.fen); err != nil {
+			t.Fatalf("LoadFEN(%q): %v", tt.fen, err)
+		}
+		err := g.ValidatePosition()
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("ValidatePosition(%q) = %v, want no error", tt.fen, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("ValidatePosition(%q) = %v, want an error about %q", tt.fen, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/colornames"
+	"math"
+	"unicode"
+)
+
+// TextInput is a single line text box. Clicking it or tabbing to it gives it focus, and Enter calls onSubmit with
+// the text. placeholder is shown in gray while the box is empty and unfocused. Escape hands focus back. Text too
+// long for the box is cut off at the start, so the end being typed stays in view.
+type TextInput struct {
+	widgetBase
+	text        string
+	placeholder string
+	maxLen      int
+	onSubmit    func(text string)
+	chars       []rune
+}
+
+func NewTextInput(placeholder string, maxLen int, onSubmit func(text string)) *TextInput {
+	return &TextInput{placeholder: placeholder, maxLen: maxLen, onSubmit: onSubmit}
+}
+
+func (t *TextInput) Text() string {
+	return t.text
+}
+
+func (t *TextInput) SetText(s string) {
+	t.text = s
+}
+
+func (t *TextInput) Key(ui *UI) {
+	t.chars = ebiten.AppendInputChars(t.chars[:0])
+	for _, r := range t.chars {
+		if len([]rune(t.text)) < t.maxLen && unicode.IsPrint(r) {
+			t.text += string(r)
+		}
+	}
+
+	if keyRepeated(ebiten.KeyBackspace) && len(t.text) > 0 {
+		runes := []rune(t.text)
+		t.text = string(runes[:len(runes)-1])
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && t.text != "" && t.onSubmit != nil {
+		t.onSubmit(t.text)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && !ui.Modal() {
+		ui.Focus(nil)
+	}
+}
+
+func (t *TextInput) Draw(dst *ebiten.Image, ui *UI) {
+	focused := ui.Focused() == Widget(t)
+	ui.drawBox(dst, t.rect, focused || ui.Highlighted(t))
+
+	label := t.text
+	textColor := colornames.Whitesmoke
+	if focused {
+		label += "_"
+	} else if t.text == "" {
+		label = t.placeholder
+		textColor = colornames.Gray
+	}
+	// drop characters from the start until the text fits, leaving some padding either side
+	runes := []rune(label)
+	for len(runes) > 1 && float64(text.BoundString(ui.theme.Font, string(runes)).Dx()) > t.rect.W-24*ui.theme.Scale {
+		runes = runes[1:]
+	}
+	label = string(runes)
+	x, y := textPosIn(ui.theme.Font, label, t.rect)
+	text.Draw(dst, label, ui.theme.Font, x, y, textColor)
+}
+
+// Slider picks a value between min and max, rounded to a multiple of step (when step isn't 0). It is dragged with
+// the mouse or stepped with the left and right arrow keys, and onChange is called whenever the value changes.
+// format turns the value into the slider's label, ex. "Volume 80%".
+type Slider struct {
+	widgetBase
+	min      float64
+	max      float64
+	step     float64
+	value    float64
+	format   func(value float64) string
+	onChange func(value float64)
+}
+
+func NewSlider(min, max, step, value float64, onChange func(value float64)) *Slider {
+	return &Slider{min: min, max: max, step: step, value: value, onChange: onChange}
+}
+
+func (s *Slider) Value() float64 {
+	return s.value
+}
+
+// SetFormat sets the function that turns the value into the slider's label
+func (s *Slider) SetFormat(format func(value float64) string) {
+	s.format = format
+}
+
+// SetValue moves the slider without calling onChange
+func (s *Slider) SetValue(value float64) {
+	s.value = s.clamp(value)
+}
+
+func (s *Slider) clamp(value float64) float64 {
+	if s.step > 0 {
+		value = s.min + math.Round((value-s.min)/s.step)*s.step
+	}
+	return math.Min(math.Max(value, s.min), s.max)
+}
+
+func (s *Slider) change(value float64) {
+	value = s.clamp(value)
+	if value != s.value {
+		s.value = value
+		if s.onChange != nil {
+			s.onChange(value)
+		}
+	}
+}
+
+func (s *Slider) Press(ui *UI, x, y int) {
+	s.Drag(ui, x, y)
+}
+
+func (s *Slider) Drag(ui *UI, x, y int) {
+	t := (float64(x) - s.rect.X) / s.rect.W
+	s.change(s.min + t*(s.max-s.min))
+}
+
+func (s *Slider) Scroll(ui *UI, dy float64) {
+	s.change(s.value + math.Copysign(s.keyStep(), dy))
+}
+
+func (s *Slider) Key(ui *UI) {
+	if keyRepeated(ebiten.KeyArrowLeft) {
+		s.change(s.value - s.keyStep())
+	}
+	if keyRepeated(ebiten.KeyArrowRight) {
+		s.change(s.value + s.keyStep())
+	}
+}
+
+// keyStep is how far one key press moves the slider, a tenth of its range when it has no step
+func (s *Slider) keyStep() float64 {
+	if s.step > 0 {
+		return s.step
+	}
+	return (s.max - s.min) / 10
+}
+
+func (s *Slider) Draw(dst *ebiten.Image, ui *UI) {
+	ui.drawBox(dst, s.rect, ui.Highlighted(s))
+
+	inset := 3 * ui.theme.Scale
+	filled := 0.0
+	if s.max > s.min {
+		filled = (s.value - s.min) / (s.max - s.min)
+	}
+	ebitenutil.DrawRect(dst, s.rect.X+inset, s.rect.Y+inset, (s.rect.W-2*inset)*filled, s.rect.H-2*inset, widgetAccentColor)
+
+	label := fmt.Sprintf("%.2f", s.value)
+	if s.format != nil {
+		label = s.format(s.value)
+	}
+	x, y := textPosIn(ui.theme.Font, label, s.rect)
+	text.Draw(dst, label, ui.theme.Font, x, y, colornames.Whitesmoke)
+}
+
+// Dropdown shows the selected option, and opens a list of all its options when clicked. While open, the list is
+// drawn over the rest of the screen by the UI. The arrow keys change the selection, and onChange is called with
+// the index of the newly selected option.
+type Dropdown struct {
+	widgetBase
+	options  []string
+	selected int
+	onChange func(index int)
+}
+
+func NewDropdown(options []string, selected int, onChange func(index int)) *Dropdown {
+	return &Dropdown{options: options, selected: selected, onChange: onChange}
+}
+
+func (d *Dropdown) Selected() int {
+	return d.selected
+}
+
+// Select selects option i without calling onChange
+func (d *Dropdown) Select(i int) {
+	d.selected = i
+}
+
+func (d *Dropdown) open(ui *UI) bool {
+	return ui.popup == d
+}
+
+// optionRect is where option i is drawn while the dropdown is open. The list opens upwards if it would run off the
+// bottom of the screen.
+func (d *Dropdown) optionRect(ui *UI, i int) Rect {
+	top := d.rect.Y + d.rect.H
+	if top+float64(len(d.options))*d.rect.H > ui.screen.H && ui.screen.H > 0 {
+		top = d.rect.Y - float64(len(d.options))*d.rect.H
+	}
+	return Rect{X: d.rect.X, Y: top + float64(i)*d.rect.H, W: d.rect.W, H: d.rect.H}
+}
+
+// optionAt returns the index of the option under x, y in the open list, or -1
+func (d *Dropdown) optionAt(ui *UI, x, y int) int {
+	for i := range d.options {
+		if d.optionRect(ui, i).Contains(x, y) {
+			return i
+		}
+	}
+	return -1
+}
+
+// popupContains is used by the UI's hit-testing while the dropdown is open
+func (d *Dropdown) popupContains(ui *UI, x, y int) bool {
+	return d.optionAt(ui, x, y) != -1
+}
+
+func (d *Dropdown) Press(ui *UI, x, y int) {
+	if !d.open(ui) {
+		ui.popup = d
+		return
+	}
+	if i := d.optionAt(ui, x, y); i != -1 {
+		d.choose(i)
+	}
+	ui.popup = nil
+}
+
+func (d *Dropdown) Key(ui *UI) {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		if d.open(ui) {
+			ui.popup = nil
+		} else {
+			ui.popup = d
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		ui.popup = nil
+	case keyRepeated(ebiten.KeyArrowUp):
+		d.choose(clampInt(d.selected-1, 0, len(d.options)-1))
+	case keyRepeated(ebiten.KeyArrowDown):
+		d.choose(clampInt(d.selected+1, 0, len(d.options)-1))
+	}
+}
+
+func (d *Dropdown) choose(i int) {
+	if i != d.selected {
+		d.selected = i
+		if d.onChange != nil {
+			d.onChange(i)
+		}
+	}
+}
+
+func (d *Dropdown) Draw(dst *ebiten.Image, ui *UI) {
+	ui.drawBox(dst, d.rect, ui.Highlighted(d) || d.open(ui))
+	if d.selected >= 0 && d.selected < len(d.options) {
+		x, y := ui.textLeftIn(d.options[d.selected], d.rect)
+		text.Draw(dst, d.options[d.selected], ui.theme.Font, x, y, colornames.Whitesmoke)
+	}
+	// a small arrow on the right shows that the dropdown opens
+	arrow := "v"
+	bounds := text.BoundString(ui.theme.Font, arrow)
+	_, y := textPosIn(ui.theme.Font, arrow, d.rect)
+	text.Draw(dst, arrow, ui.theme.Font, int(d.rect.X+d.rect.W-12*ui.theme.Scale)-bounds.Dx(), y, colornames.Gray)
+}
+
+// DrawPopup draws the open list of options, highlighting the selected option and the one under the cursor
+func (d *Dropdown) DrawPopup(dst *ebiten.Image, ui *UI) {
+	x, y := ebiten.CursorPosition()
+	hovered := d.optionAt(ui, x, y)
+	for i, option := range d.options {
+		r := d.optionRect(ui, i)
+		ui.drawBox(dst, r, i == hovered)
+		if i == d.selected {
+			inset := 3 * ui.theme.Scale
+			ebitenutil.DrawRect(dst, r.X+inset, r.Y+inset, r.W-2*inset, r.H-2*inset, widgetAccentColor)
+		}
+		tx, ty := ui.textLeftIn(option, r)
+		text.Draw(dst, option, ui.theme.Font, tx, ty, colornames.Whitesmoke)
+	}
+}
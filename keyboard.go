@@ -0,0 +1,178 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/colornames"
+	"strings"
+)
+
+// MoveInputMaxLen is plenty for the longest SAN or UCI move, ex. "Qh4xe1+" or "O-O-O"
+const MoveInputMaxLen = 8
+
+// keyRepeated reports whether a held key should trigger this tick. Fires on the first tick, then repeats
+// after a short delay so the cursor can be swept across the board.
+func keyRepeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || (d >= 15 && d%4 == 0)
+}
+
+// UpdateMainMenuKeyboard lets the player start a game without the mouse. The arrow keys move between the buttons
+// as well as Tab.
+func (g *Game) UpdateMainMenuKeyboard() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		g.menuUI.FocusNext(1)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		g.menuUI.FocusNext(-1)
+	}
+	g.menuUI.UpdateKeyboard()
+}
+
+// UpdateKeyboard handles in-game keyboard play. Tab and Shift+Tab cycle focus between the board and the widgets of
+// gameUI. On the board, the arrow keys move the cursor (selectedRow, selectedCol) and Enter picks up or drops a
+// piece. The move entry box accepts SAN or UCI moves, submitted with Enter.
+func (g *Game) UpdateKeyboard() {
+	if g.gameUI.UpdateKeyboard() {
+		g.keyboardMode = true
+
+		// a piece can't stay in hand once the board loses focus
+		if g.keyboardHeld && g.gameUI.Focused() != nil {
+			g.DropKeyboardPiece(false)
+		}
+	}
+
+	if !g.gameUI.Typing() && !g.gameUI.Modal() && inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		g.FlipBoard()
+	}
+
+	if g.gameUI.Focused() == nil && !g.gameUI.Modal() {
+		g.UpdateBoardKeyboard()
+	}
+}
+
+// UpdateBoardKeyboard moves the board cursor with the arrow keys and picks up or drops pieces with Enter/Space
+func (g *Game) UpdateBoardKeyboard() {
+	// arrow keys follow the board as it is drawn, so "up" is always towards the top of the screen
+	dir := 1
+	if g.BoardFlipped() {
+		dir = -1
+	}
+
+	moved := true
+	switch {
+	case keyRepeated(ebiten.KeyArrowUp):
+		g.selectedRow -= dir
+	case keyRepeated(ebiten.KeyArrowDown):
+		g.selectedRow += dir
+	case keyRepeated(ebiten.KeyArrowLeft):
+		g.selectedCol -= dir
+	case keyRepeated(ebiten.KeyArrowRight):
+		g.selectedCol += dir
+	default:
+		moved = false
+	}
+
+	if moved {
+		g.keyboardMode = true
+		g.selectedRow = clampInt(g.selectedRow, 0, 7)
+		g.selectedCol = clampInt(g.selectedCol, 0, 7)
+		if g.keyboardHeld {
+			g.selectedLocation[0], g.selectedLocation[1] = g.SquareCenter(g.selectedRow, g.selectedCol)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && g.keyboardHeld {
+		g.DropKeyboardPiece(false)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.keyboardMode = true
+		if g.keyboardHeld {
+			g.DropKeyboardPiece(true)
+		} else if g.selectedPiece == -1 && !g.gameOver {
+			piece := g.PieceOn(g.selectedRow, g.selectedCol)
+			if piece != nil && piece.White() == g.whitesTurn {
+				g.selectedPiece = g.PieceIndex(piece)
+				g.keyboardHeld = true
+				g.selectedLocation[0], g.selectedLocation[1] = g.SquareCenter(g.selectedRow, g.selectedCol)
+				g.scheduleDraw = true
+			}
+		}
+	}
+}
+
+// DropKeyboardPiece releases a piece that was picked up with the keyboard. If attemptMove is true, the piece is
+// played to the cursor square when legal. Otherwise, it goes back where it came from.
+func (g *Game) DropKeyboardPiece(attemptMove bool) {
+	piece := g.pieces[g.selectedPiece]
+	if attemptMove && (piece.Row() != g.selectedRow || piece.Col() != g.selectedCol) {
+		g.DropPiece(piece, g.selectedRow, g.selectedCol)
+	} else if piece.Row() != g.selectedRow || piece.Col() != g.selectedCol {
+		g.Animate(piece, g.selectedLocation[0], g.selectedLocation[1], false, SnapBackAnimDuration)
+	}
+	g.keyboardHeld = false
+	g.selectedPiece = -1
+	g.scheduleDraw = true
+}
+
+// MouseTakesOver hands control back from the keyboard to the mouse. A piece the keyboard was carrying goes back to
+// its square, so the next click or release doesn't play it to wherever the cursor happens to be.
+func (g *Game) MouseTakesOver() {
+	if g.keyboardHeld && g.selectedPiece != -1 {
+		g.DropKeyboardPiece(false)
+	}
+	g.keyboardMode = false
+	g.keyboardHeld = false
+}
+
+// SubmitMove plays a move typed into the move entry box, or explains why it couldn't be played
+func (g *Game) SubmitMove(move string) {
+	if err := g.MakeMoveFromNotation(move); err != nil {
+		g.moveInputMsg = err.Error()
+	} else {
+		g.moveInputMsg = ""
+		g.moveInputBox.SetText("")
+	}
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// DrawMoveInput draws the reason the last typed move was rejected below the move entry box. The box itself is a
+// widget of gameUI.
+func (g *Game) DrawMoveInput() {
+	// wrap the message so it stays clear of the board
+	msgY := g.layout.MessageY
+	for _, line := range wrapText(g.moveInputMsg, 20) {
+		text.Draw(g.uiImage, line, g.uiFontSmall, int(g.layout.MoveInput.X), int(msgY), colornames.Darkred)
+		msgY += 22 * g.layout.Scale
+	}
+}
+
+// wrapText splits s into lines of at most width characters, breaking on spaces where possible
+func wrapText(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestMouseTakesOverFromKeyboard(t *testing.T) {
+	g := &Game{}
+	if err := g.LoadFEN(StartingFEN); err != nil {
+		t.Fatal(err)
+	}
+
+	// the e2 pawn is picked up with the keyboard and carried to e4
+	pawn := g.PieceOn(6, 4)
+	g.selectedPiece = g.PieceIndex(pawn)
+	g.keyboardMode = true
+	g.keyboardHeld = true
+	g.selectedRow, g.selectedCol = 4, 4
+
+	g.MouseTakesOver()
+
+	if g.selectedPiece != -1 || g.keyboardHeld || g.keyboardMode {
+		t.Fatalf("selectedPiece = %d, keyboardHeld = %v, keyboardMode = %v after the mouse took over, want -1, false, false",
+			g.selectedPiece, g.keyboardHeld, g.keyboardMode)
+	}
+	if g.PieceOn(6, 4) != pawn || g.PieceOn(4, 4) != nil || !g.whitesTurn || len(g.moves) != 0 {
+		t.Errorf("the carried pawn was played, position is now %s", g.FEN())
+	}
+}
+
+func TestMouseTakesOverWithNothingHeld(t *testing.T) {
+	g := &Game{}
+	if err := g.LoadFEN(StartingFEN); err != nil {
+		t.Fatal(err)
+	}
+
+	// a piece dragged by the mouse isn't the keyboard's to drop
+	g.selectedPiece = g.PieceIndex(g.PieceOn(7, 6))
+	g.keyboardMode = true
+
+	g.MouseTakesOver()
+
+	if g.selectedPiece == -1 || g.keyboardMode {
+		t.Errorf("selectedPiece = %d, keyboardMode = %v, want the dragged piece kept and keyboard mode off",
+			g.selectedPiece, g.keyboardMode)
+	}
+}
@@ -7,9 +7,9 @@ type King struct {
 }
 
 // Moves returns a slice of all valid moves for given Piece. Each valid move in the slice is stored
-// in an array with a length of two-- Row and Col.
-func (p *King) Moves(g Game) [][2]int {
-	moves := make([][2]int, 0)
+// as a Move, with the flags and captured piece filled in.
+func (p *King) Moves(g Game) []Move {
+	moves := make([]Move, 0)
 
 	possibleMoves := [8][2]int{
 		{p.row, p.col + 1},
@@ -24,9 +24,9 @@ func (p *King) Moves(g Game) [][2]int {
 
 	for _, move := range possibleMoves {
 		if IsInBounds(move[0], move[1]) {
-			otherPiece := GetPieceOnSquare(move[0], move[1], g.pieces)
+			otherPiece := g.PieceOn(move[0], move[1])
 			if otherPiece == nil || otherPiece.White() != p.white {
-				moves = append(moves, [2]int{move[0], move[1]})
+				moves = append(moves, g.NewMove(p, move[0], move[1]))
 			}
 		}
 	}
@@ -41,14 +41,16 @@ func (p *King) Moves(g Game) [][2]int {
 				//check all positions between for pieces
 				legal := true
 				for checkCol := 1; checkCol < 4; checkCol++ {
-					if GetPieceOnSquare(p.row, checkCol, g.pieces) != nil {
+					if g.PieceOn(p.row, checkCol) != nil {
 						legal = false
 						break
 					}
 				}
 				//add appropriate king move to slice
 				if legal {
-					moves = append(moves, [2]int{p.row, p.col - 2})
+					castle := g.NewMove(p, p.row, p.col-2)
+					castle.Flags |= FlagCastle
+					moves = append(moves, castle)
 				}
 			}
 			if g.whiteCastles[1] {
@@ -56,14 +58,16 @@ func (p *King) Moves(g Game) [][2]int {
 				//check all positions between for pieces
 				legal := true
 				for checkCol := 6; checkCol > 4; checkCol-- {
-					if GetPieceOnSquare(p.row, checkCol, g.pieces) != nil {
+					if g.PieceOn(p.row, checkCol) != nil {
 						legal = false
 						break
 					}
 				}
 				//add appropriate king move to slice
 				if legal {
-					moves = append(moves, [2]int{p.row, p.col + 2})
+					castle := g.NewMove(p, p.row, p.col+2)
+					castle.Flags |= FlagCastle
+					moves = append(moves, castle)
 				}
 			}
 		} else {
@@ -72,14 +76,16 @@ func (p *King) Moves(g Game) [][2]int {
 				//check all positions between for pieces
 				legal := true
 				for checkCol := 1; checkCol < 4; checkCol++ {
-					if GetPieceOnSquare(p.row, checkCol, g.pieces) != nil {
+					if g.PieceOn(p.row, checkCol) != nil {
 						legal = false
 						break
 					}
 				}
 				//add appropriate king move to slice
 				if legal {
-					moves = append(moves, [2]int{p.row, p.col - 2})
+					castle := g.NewMove(p, p.row, p.col-2)
+					castle.Flags |= FlagCastle
+					moves = append(moves, castle)
 				}
 			}
 			if g.blackCastles[1] {
@@ -87,14 +93,16 @@ func (p *King) Moves(g Game) [][2]int {
 				//check all positions between for pieces
 				legal := true
 				for checkCol := 6; checkCol > 4; checkCol-- {
-					if GetPieceOnSquare(p.row, checkCol, g.pieces) != nil {
+					if g.PieceOn(p.row, checkCol) != nil {
 						legal = false
 						break
 					}
 				}
 				//add appropriate king move to slice
 				if legal {
-					moves = append(moves, [2]int{p.row, p.col + 2})
+					castle := g.NewMove(p, p.row, p.col+2)
+					castle.Flags |= FlagCastle
+					moves = append(moves, castle)
 				}
 			}
 		}
@@ -9,9 +9,9 @@ type Knight struct {
 }
 
 // Moves returns a slice of all valid moves for given Piece. Each valid move in the slice is stored
-// in an array with a length of two-- Row and Col.
-func (p *Knight) Moves(g Game) [][2]int {
-	moves := make([][2]int, 0)
+// as a Move, with the flags and captured piece filled in.
+func (p *Knight) Moves(g Game) []Move {
+	moves := make([]Move, 0)
 
 	possibleMoves := [8][2]int{
 		{p.row + 2, p.col + 1},
@@ -26,9 +26,9 @@ func (p *Knight) Moves(g Game) [][2]int {
 
 	for _, move := range possibleMoves {
 		if IsInBounds(move[0], move[1]) {
-			otherPiece := GetPieceOnSquare(move[0], move[1], g.pieces)
+			otherPiece := g.PieceOn(move[0], move[1])
 			if otherPiece == nil || otherPiece.White() != p.white {
-				moves = append(moves, [2]int{move[0], move[1]})
+				moves = append(moves, g.NewMove(p, move[0], move[1]))
 			}
 		}
 	}
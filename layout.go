@@ -0,0 +1,215 @@
+package main
+
+import "math"
+
+// Rect is an axis-aligned rectangle in screen pixels
+type Rect struct {
+	X float64
+	Y float64
+	W float64
+	H float64
+}
+
+// Contains reports whether the point x, y (ex. the cursor position) is inside the rectangle
+func (r Rect) Contains(x, y int) bool {
+	return float64(x) >= r.X && float64(x) <= r.X+r.W && float64(y) >= r.Y && float64(y) <= r.Y+r.H
+}
+
+// TakenRow is where one side's taken pieces are drawn. With n pieces in the row, piece i has its top left corner at
+// X + (n-i)*Step, Y, so each piece overlaps the one before it and the row grows away from the board.
+type TakenRow struct {
+	X    float64
+	Y    float64
+	Step float64
+}
+
+// ScreenLayout is where everything goes on screen, worked out by ComputeLayout from the screen size. Draw places
+// things with these rects and Update hit-tests against the very same rects, so the two can't drift apart.
+//
+// Scale is the size of the UI relative to the 1920x1080 design resolution (Width x Height), used for buttons,
+// fonts and taken pieces. The board has its own size and is drawn from board space (BoardSize x BoardSize) to the
+// Board rect. Portrait layouts stack the board above the controls instead of putting the controls beside it.
+// OpeningX, OpeningY is where the name of the opening starts, in the info panel.
+// The board editor shares the Board rect, with its own controls and piece palette laid out in the same panels.
+type ScreenLayout struct {
+	Width       int
+	Height      int
+	Scale       float64
+	Portrait    bool
+	Board       Rect
+	Controls    Rect
+	Info        Rect
+	Buttons     [InGameButtonCount]Rect
+	MoveInput   Rect
+	StatusY     float64
+	MessageY    float64
+	OpeningX    float64
+	OpeningY    float64
+	TakenRows   [2]TakenRow
+	MenuButtons [3]Rect
+	MenuTitleY  float64
+
+	EditorControls [EditorControlCount]Rect
+	EditorFEN      Rect
+	EditorPalette  [PalettePieceCount]Rect
+	EditorMessageY float64
+}
+
+const (
+	// BoardSize is the width and height of the board layers in board space, before they are scaled to fit Board
+	BoardSize = TileSize * 8
+	// BoardMargin is the fraction of the screen height kept free around the board in landscape layouts
+	BoardMargin = 0.08
+	// PanelWidth is the minimum width kept beside the board for the controls and info panels, in design pixels
+	PanelWidth = 260
+	// ControlsHeight is the height below the board needed for the controls in portrait layouts, in design pixels
+	ControlsHeight = 520
+	// TakenPieceSize and TakenPieceStep are the size of a taken piece and how far each one is offset from the
+	// one before it, in design pixels
+	TakenPieceSize = 60
+	TakenPieceStep = 24
+	// BtnGap is the space between stacked buttons, in design pixels
+	BtnGap = 14
+	// PaletteSlotSize is the size of a piece in the board editor's palette, in design pixels
+	PaletteSlotSize = 96
+)
+
+// ComputeLayout works out the layout for a screen of width x height pixels. Wide screens get the controls and info
+// panels either side of the board, and tall screens get them stacked above and below it.
+func ComputeLayout(width, height int) ScreenLayout {
+	w, h := float64(width), float64(height)
+	l := ScreenLayout{Width: width, Height: height, Portrait: h > w}
+
+	if !l.Portrait {
+		// ultra-wide screens are limited by height, so the UI doesn't grow past what fits vertically
+		l.Scale = math.Min(w/Width, h/Height)
+		boardSize := math.Max(math.Min(h*(1-BoardMargin), w-2*PanelWidth*l.Scale), 1)
+		l.Board = Rect{X: (w - boardSize) / 2, Y: (h - boardSize) / 2, W: boardSize, H: boardSize}
+		l.Controls = Rect{X: 0, Y: 0, W: l.Board.X, H: h}
+		l.Info = Rect{X: l.Board.X + boardSize, Y: 0, W: w - l.Board.X - boardSize, H: h}
+
+		// taken pieces run along the outside of the board, at the top on the left and at the bottom on the right
+		l.TakenRows[0] = TakenRow{X: l.Board.X - TakenPieceSize*l.Scale, Y: l.Board.Y + TakenPieceSize*l.Scale, Step: -TakenPieceStep * l.Scale}
+		l.TakenRows[1] = TakenRow{X: l.Board.X + boardSize + TakenPieceSize*l.Scale, Y: l.Board.Y + boardSize - TakenPieceSize*l.Scale, Step: TakenPieceStep * l.Scale}
+
+		// Main Menu and New Game sit either side of the vertical center with the board orientation buttons stacked
+		// above them and the move entry box below
+		btnW, btnH, gap := BtnWidth*l.Scale, BtnHeight*l.Scale, BtnGap*l.Scale
+		btnX := l.Controls.X + (l.Controls.W-btnW)/2
+		centerY := h / 2
+		l.Buttons[0] = Rect{X: btnX, Y: centerY - btnH - gap/2, W: btnW, H: btnH}
+		l.Buttons[1] = Rect{X: btnX, Y: centerY + gap/2, W: btnW, H: btnH}
+		l.Buttons[2] = Rect{X: btnX, Y: l.Buttons[0].Y - 2*(btnH+gap), W: btnW, H: btnH}
+		l.Buttons[3] = Rect{X: btnX, Y: l.Buttons[0].Y - (btnH + gap), W: btnW, H: btnH}
+		l.MoveInput = Rect{X: btnX, Y: l.Buttons[1].Y + btnH + gap, W: btnW, H: btnH}
+		l.StatusY = l.Buttons[2].Y - 24*l.Scale
+
+		// Resign and Offer Draw are kept away from the other buttons, in the info panel
+		infoBtnX := l.Info.X + (l.Info.W-btnW)/2
+		l.Buttons[4] = Rect{X: infoBtnX, Y: centerY - btnH - gap/2, W: btnW, H: btnH}
+		l.Buttons[5] = Rect{X: infoBtnX, Y: centerY + gap/2, W: btnW, H: btnH}
+		l.OpeningX = infoBtnX
+		l.OpeningY = l.Buttons[4].Y - 2*(btnH+gap)
+	} else {
+		// the design resolution turned on its side
+		l.Scale = math.Min(w/Height, h/Width)
+		takenH := (TakenPieceSize + 20) * l.Scale
+		infoH := 60 * l.Scale
+		boardSize := math.Max(math.Min(w*(1-BoardMargin/2), h-infoH-2*takenH-ControlsHeight*l.Scale), 1)
+		l.Info = Rect{X: 0, Y: 0, W: w, H: infoH}
+		l.Board = Rect{X: (w - boardSize) / 2, Y: infoH + takenH, W: boardSize, H: boardSize}
+		l.Controls = Rect{X: 0, Y: l.Board.Y + boardSize + takenH, W: w, H: h - l.Board.Y - boardSize - takenH}
+
+		// taken pieces run above and below the board, starting from its left edge
+		l.TakenRows[0] = TakenRow{X: l.Board.X - TakenPieceStep*l.Scale, Y: l.Board.Y - takenH + 10*l.Scale, Step: TakenPieceStep * l.Scale}
+		l.TakenRows[1] = TakenRow{X: l.Board.X - TakenPieceStep*l.Scale, Y: l.Board.Y + boardSize + 10*l.Scale, Step: TakenPieceStep * l.Scale}
+
+		// two columns of buttons, the board orientation buttons on top, then Resign and Offer Draw, then the move
+		// entry box
+		btnW, btnH, gap := BtnWidth*l.Scale, BtnHeight*l.Scale, BtnGap*l.Scale
+		leftX := w/2 - gap/2 - btnW
+		rightX := w/2 + gap/2
+		topY := l.Controls.Y + 40*l.Scale
+		l.Buttons[2] = Rect{X: leftX, Y: topY, W: btnW, H: btnH}
+		l.Buttons[3] = Rect{X: rightX, Y: topY, W: btnW, H: btnH}
+		l.Buttons[0] = Rect{X: leftX, Y: topY + btnH + gap, W: btnW, H: btnH}
+		l.Buttons[1] = Rect{X: rightX, Y: topY + btnH + gap, W: btnW, H: btnH}
+		l.Buttons[4] = Rect{X: leftX, Y: topY + 2*(btnH+gap), W: btnW, H: btnH}
+		l.Buttons[5] = Rect{X: rightX, Y: topY + 2*(btnH+gap), W: btnW, H: btnH}
+		l.MoveInput = Rect{X: leftX, Y: topY + 3*(btnH+gap), W: btnW, H: btnH}
+		l.StatusY = topY - 14*l.Scale
+		l.OpeningX = l.Board.X
+		l.OpeningY = l.Info.Y + 24*l.Scale
+	}
+	l.MessageY = l.MoveInput.Y + l.MoveInput.H + 30*l.Scale
+
+	menuBtnX := (w - BtnWidth*l.Scale) / 2
+	for i := range l.MenuButtons {
+		l.MenuButtons[i] = Rect{X: menuBtnX, Y: h/2 + float64(8+110*i)*l.Scale, W: BtnWidth * l.Scale, H: BtnHeight * l.Scale}
+	}
+	l.MenuTitleY = h * 0.4
+
+	l.computeEditorLayout()
+	return l
+}
+
+// computeEditorLayout places the board editor's controls in a grid with the FEN box along the bottom, beside the
+// board in landscape and below it in portrait. The palette gets the info panel in landscape, and a single row
+// under the board in portrait. Controls shrink to fit narrow panels.
+func (l *ScreenLayout) computeEditorLayout() {
+	btnW, btnH, gap := BtnWidth*l.Scale, BtnHeight*l.Scale, BtnGap*l.Scale
+	columns, paletteColumns := 2, 2
+	if l.Portrait {
+		columns, paletteColumns = 4, PalettePieceCount
+	}
+	rows := (EditorControlCount + columns - 1) / columns
+	cols := float64(columns)
+	btnW = math.Min(btnW, (l.Controls.W-(cols+1)*gap)/cols)
+	gridW := cols*btnW + (cols-1)*gap
+	gridX := l.Controls.X + (l.Controls.W-gridW)/2
+
+	// the grid and the FEN box below it are centered vertically in landscape, and start at the top in portrait
+	gridY := l.Controls.Y + (l.Controls.H-float64(rows+1)*(btnH+gap))/2
+	if l.Portrait {
+		gridY = l.Controls.Y + 20*l.Scale
+	}
+	for i := range l.EditorControls {
+		l.EditorControls[i] = Rect{X: gridX + float64(i%columns)*(btnW+gap), Y: gridY + float64(i/columns)*(btnH+gap), W: btnW, H: btnH}
+	}
+	l.EditorFEN = Rect{X: gridX, Y: gridY + float64(rows)*(btnH+gap), W: gridW, H: btnH}
+	l.EditorMessageY = l.EditorFEN.Y + l.EditorFEN.H + 30*l.Scale
+
+	// white pieces first, then black, a side to a column (or half a row) each
+	slot := PaletteSlotSize * l.Scale
+	paletteRows := PalettePieceCount / paletteColumns
+	area := l.Info
+	if l.Portrait {
+		slot = math.Min(slot, l.Board.W/PalettePieceCount)
+		area = Rect{X: l.Board.X, Y: l.Board.Y + l.Board.H, W: l.Board.W, H: l.Controls.Y - l.Board.Y - l.Board.H}
+	} else {
+		slot = math.Min(slot, (area.W-gap)/2)
+	}
+	paletteX := area.X + (area.W-float64(paletteColumns)*slot)/2
+	paletteY := area.Y + (area.H-float64(paletteRows)*slot)/2
+	for i := range l.EditorPalette {
+		column, row := i/paletteRows, i%paletteRows
+		l.EditorPalette[i] = Rect{X: paletteX + float64(column)*slot, Y: paletteY + float64(row)*slot, W: slot, H: slot}
+	}
+}
+
+// BoardScale is how much board space is scaled by to fill the Board rect
+func (l *ScreenLayout) BoardScale() float64 {
+	return l.Board.W / BoardSize
+}
+
+// SquareAt returns the board square under the screen position x, y as seen on screen, before any flipping of the
+// board is taken into account. The row and col are clamped to the board, and onBoard is false if x, y was outside it.
+func (l *ScreenLayout) SquareAt(x, y int) (row int, col int, onBoard bool) {
+	tile := l.Board.W / 8
+	boardX := (float64(x) - l.Board.X) / tile
+	boardY := (float64(y) - l.Board.Y) / tile
+	onBoard = boardX >= 0 && boardX < 8 && boardY >= 0 && boardY < 8
+	col = int(math.Floor(math.Min(math.Max(boardX, 0), 7)))
+	row = int(math.Floor(math.Min(math.Max(boardY, 0), 7)))
+	return row, col, onBoard
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/colornames"
+	"math"
+)
+
+// ListRowHeight is the height of a row in a List, in design pixels
+const ListRowHeight = 36
+
+// List is a scrollable list of text rows. Clicking a row or moving to it with the up and down arrow keys selects
+// it and calls onSelect. Enter, or clicking the selected row again, calls onActivate with the selected row. scroll is the index of the first visible row,
+// and rows is how many rows fit.
+type List struct {
+	widgetBase
+	items      []string
+	selected   int
+	scroll     int
+	onSelect   func(index int)
+	onActivate func(index int)
+	rows       int
+}
+
+func NewList(items []string, onSelect func(index int)) *List {
+	return &List{items: items, selected: -1, onSelect: onSelect, rows: 1}
+}
+
+// SetItems replaces the rows, keeping the selection if it is still in range
+func (l *List) SetItems(items []string) {
+	l.items = items
+	if l.selected >= len(items) {
+		l.selected = -1
+	}
+	l.scroll = clampInt(l.scroll, 0, l.maxScroll())
+}
+
+// SetOnActivate sets the function called when the selected row is activated
+func (l *List) SetOnActivate(onActivate func(index int)) {
+	l.onActivate = onActivate
+}
+
+func (l *List) Selected() int {
+	return l.selected
+}
+
+// Select selects row i and scrolls it into view, without calling onSelect
+func (l *List) Select(i int) {
+	l.selected = i
+	if i < l.scroll {
+		l.scroll = i
+	} else if i >= l.scroll+l.rows {
+		l.scroll = i - l.rows + 1
+	}
+	l.scroll = clampInt(l.scroll, 0, l.maxScroll())
+}
+
+func (l *List) rowHeight(ui *UI) float64 {
+	return ListRowHeight * ui.theme.Scale
+}
+
+// visibleRows is how many rows fit in the list at the UI's current scale, which is remembered in rows for Select
+func (l *List) visibleRows(ui *UI) int {
+	l.rows = int(math.Max(1, math.Floor(l.rect.H/l.rowHeight(ui))))
+	return l.rows
+}
+
+func (l *List) maxScroll() int {
+	return int(math.Max(0, float64(len(l.items)-l.rows)))
+}
+
+func (l *List) Press(ui *UI, x, y int) {
+	i := l.scroll + int((float64(y)-l.rect.Y)/l.rowHeight(ui))
+	if i < 0 || i >= len(l.items) || i >= l.scroll+l.visibleRows(ui) {
+		return
+	}
+	if i == l.selected {
+		l.activate()
+	} else {
+		l.choose(i)
+	}
+}
+
+func (l *List) Scroll(ui *UI, dy float64) {
+	// wheel up scrolls towards the top of the list
+	if dy > 0 {
+		l.scroll--
+	} else {
+		l.scroll++
+	}
+	l.visibleRows(ui)
+	l.scroll = clampInt(l.scroll, 0, l.maxScroll())
+}
+
+func (l *List) Key(ui *UI) {
+	switch {
+	case keyRepeated(ebiten.KeyArrowUp):
+		l.choose(clampInt(l.selected-1, 0, len(l.items)-1))
+	case keyRepeated(ebiten.KeyArrowDown):
+		l.choose(clampInt(l.selected+1, 0, len(l.items)-1))
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		l.activate()
+	}
+}
+
+func (l *List) activate() {
+	if l.selected != -1 && l.onActivate != nil {
+		l.onActivate(l.selected)
+	}
+}
+
+func (l *List) choose(i int) {
+	if len(l.items) == 0 {
+		return
+	}
+	if i != l.selected {
+		l.Select(i)
+		if l.onSelect != nil {
+			l.onSelect(i)
+		}
+	}
+}
+
+func (l *List) Draw(dst *ebiten.Image, ui *UI) {
+	ui.drawBox(dst, l.rect, ui.Highlighted(l) || ui.Focused() == Widget(l))
+
+	rowH := l.rowHeight(ui)
+	rows := l.visibleRows(ui)
+	inset := 3 * ui.theme.Scale
+	for i := l.scroll; i < len(l.items) && i < l.scroll+rows; i++ {
+		r := Rect{X: l.rect.X + inset, Y: l.rect.Y + float64(i-l.scroll)*rowH, W: l.rect.W - 2*inset, H: rowH}
+		if i == l.selected {
+			ebitenutil.DrawRect(dst, r.X, r.Y+inset, r.W, r.H-inset, widgetAccentColor)
+		}
+		x, y := ui.textLeftIn(l.items[i], r)
+		text.Draw(dst, l.items[i], ui.theme.Font, x, y, colornames.Whitesmoke)
+	}
+
+	// a scroll bar along the right edge when not every row fits
+	if len(l.items) > rows {
+		barH := l.rect.H * float64(rows) / float64(len(l.items))
+		barY := l.rect.Y + (l.rect.H-barH)*float64(l.scroll)/float64(len(l.items)-rows)
+		ebitenutil.DrawRect(dst, l.rect.X+l.rect.W-inset-6*ui.theme.Scale, barY, 6*ui.theme.Scale, barH, colornames.Gray)
+	}
+}
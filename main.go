@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
 	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 	_ "github.com/silbinarywolf/preferdiscretegpu" // Fix for discrete GPUs in windows
 	"golang.org/x/image/colornames"
 	"golang.org/x/image/font"
@@ -14,16 +16,42 @@ import (
 	"math"
 	"path/filepath"
 	"sort"
+	"time"
 )
 
 // Game
-// gameType indicates the selected game mode. -1 = main menu, 0 = local multiplayer, 1 = versus a bot.
+// gameType indicates the selected game mode. -1 = main menu, 1 = local multiplayer, 2 = board editor.
 // gameImage, among the other image variables, are for rendering various "layers" of the game.
+// pieces are the pieces in play, board holds each of them on its square, and captured are the pieces that have been
+// taken, see board.go.
 // scheduleDraw is a sentinel value to indicate when static images need to be refreshed.
 // checkmateNotChecked is false until we evaluate if the previous move ends the game.
 // selectedLocations is for the x, y values of a piece in motion.
 // selectedPiece is the index of the selected piece (-1 indicates none selected).
 // selectedCol, selectedRow is the hovered over/selected board square.
+// keyboardMode is true while the keyboard drives the cursor and focus; moving or clicking the mouse ends it.
+// keyboardHeld is true when the selected piece was picked up with the keyboard rather than dragged.
+// menuUI and gameUI hold the widgets of the main menu and the game screen, drawn with theme. The board has keyboard
+// focus when no widget in gameUI does. pointerOnUI is true when gameUI claimed the mouse this tick.
+// moveInputBox is the move entry box, and moveInputMsg explains why the last entry failed.
+// resignButton reads Abort until both sides have moved, see ResignOrAbort. drawButton offers a draw.
+// result is how the game ended, with a Score of "*" while it is being played. gameOverMsg describes it.
+// moves is the game record in SAN, see RecordMove. startTime is when the game began and endTime when it ended, see
+// EndGame. colorsSwapped is true when a rematch has swapped the players' colors, so player 2 has white.
+// startFEN is the position the game began from when it was set up in the board editor, or "" for the usual start.
+// plyOffset is how many plies were played before that position, and halfmoveClock counts plies since the last
+// capture or pawn move. Both come from the FEN, see LoadFEN. editor is the board editor screen.
+// opening is the last named opening the game reached, see UpdateOpening, or empty before the game reaches one.
+// animations are the pieces currently tweening across the screen. animationSpeed scales how fast they play, with
+// 0 turning animations off.
+// boardFlipped is true when the player has flipped the board with the Flip Board button. autoFlip turns the board to
+// face the side to move in local matches. See BoardFlipped for how the two combine.
+// lastMove is the row and col the previous move was made from and to, or all -1 before the first move.
+// eventHandlers are called with each GameEvent, see OnGameEvent. sounds plays a sound effect for each event.
+// annotations are the arrows and circles drawn with the right mouse button. annotationStart is the square a
+// right-click-drag began on, or -1, -1 when not drawing.
+// layout is where everything is placed on the current screen, recomputed by Layout when the screen size changes.
+// fontSource is the parsed UI font, kept so the font faces can be recreated at fontScale when the UI is rescaled.
 // The unmentioned variables seem straightforward enough.
 type Game struct {
 	gameType            int
@@ -31,9 +59,12 @@ type Game struct {
 	boardImage          *ebiten.Image
 	movingImage         *ebiten.Image
 	pieceImage          *ebiten.Image
+	annotationImage     *ebiten.Image
 	uiImage             *ebiten.Image
 	menuBgImage         *ebiten.Image
-	pieces              [32]ChessPiece
+	pieces              []ChessPiece
+	board               [8][8]ChessPiece
+	captured            []ChessPiece
 	scheduleDraw        bool
 	whitesTurn          bool
 	inCheck             bool
@@ -48,28 +79,64 @@ type Game struct {
 	blackCastles        [2]bool
 	gameOver            bool
 	gameOverMsg         string
+	result              GameResult
+	moves               []string
+	startTime           time.Time
+	endTime             time.Time
+	colorsSwapped       bool
+	startFEN            string
+	plyOffset           int
+	halfmoveClock       int
+	editor              *BoardEditor
+	opening             Opening
 	uiFontBig           font.Face
 	uiFont              font.Face
 	uiFontSmall         font.Face
-	btnHoverIndex       int
-	btnPrimary          *ebiten.Image
-	btnPrimaryHover     *ebiten.Image
-	btnInfo             *ebiten.Image
-	btnInfoHover        *ebiten.Image
-	scaleX              float64
-	scaleY              float64
-	factor              float64
-	screenSize          [2]int
-	mainMenuButtons     [2]Button
-	inGameButtons       [2]Button
+	theme               Theme
+	menuUI              *UI
+	gameUI              *UI
+	pointerOnUI         bool
+	mainMenuButtons     [3]*Button
+	inGameButtons       [InGameButtonCount]Widget
+	moveInputBox        *TextInput
+	resignButton        *Button
+	drawButton          *Button
+	keyboardMode        bool
+	keyboardHeld        bool
+	lastCursor          [2]int
+	moveInputMsg        string
+	animations          []Animation
+	animationSpeed      float64
+	annotations         []Annotation
+	annotationStart     [2]int
+	boardFlipped        bool
+	autoFlip            bool
+	lastMove            [2][2]int
+	eventHandlers       []func(GameEvent)
+	sounds              *Sounds
+	layout              ScreenLayout
+	fontSource          *opentype.Font
+	fontScale           float64
 }
 
+const (
+	MoveDotRadius        = 20
+	CaptureRingRadius    = 62
+	CaptureRingThickness = 10
+)
+
 const (
 	Width    = 1920
 	Height   = 1080
 	TileSize = 128
 	FontDPI  = 72
 	Filter   = ebiten.FilterLinear
+	// PieceImageSize is the size of the piece images, drawn at PieceScale on a board tile
+	PieceImageSize = 60
+	PieceScale     = 1.5
+	// InGameButtonCount is the number of buttons beside the board: Main Menu, New Game, Flip Board, AutoFlip,
+	// Resign and Offer Draw
+	InGameButtonCount = 6
 )
 
 // Draw
@@ -77,69 +144,42 @@ const (
 func (g *Game) Draw(screen *ebiten.Image) {
 
 	screen.Fill(color.RGBA{R: 0x13, G: 0x33, B: 0x31, A: 0xff})
-	//store screen size
-	g.screenSize[0], g.screenSize[1] = screen.Size()
+	g.ResizeScreenImages(screen.Size())
+	w, h := g.layout.Width, g.layout.Height
 
 	switch g.gameType {
 	case -1:
 		g.DrawMainMenu(false) //Prints to g.uiImage and g.menuBgImage
 
 		//Using selectedCol as a counter for infinite scroll of the background
+		//The background is generated at the design resolution, so it is scaled up to cover the screen
 		g.selectedCol = (g.selectedCol + 1) % 50
+		bgScale := 1.3 * math.Max(float64(w)/Width, float64(h)/Height)
 		opMenuBg := &ebiten.DrawImageOptions{}
 		opMenuBg.Filter = Filter
 		opMenuBg.GeoM.Translate(float64(-g.selectedCol*2), float64(-150+g.selectedCol*2))
-		opMenuBg.GeoM.Scale(1.3, 1.3)
+		opMenuBg.GeoM.Scale(bgScale, bgScale)
 		screen.DrawImage(g.menuBgImage, opMenuBg)
 		screen.DrawImage(g.uiImage, &ebiten.DrawImageOptions{})
 
-		menuTextY := int(float64(g.screenSize[1]) * 0.4)
-		text.Draw(screen, "Chess", g.uiFontBig, g.screenSize[0]/2-207, menuTextY, colornames.White)
-		text.Draw(screen, "by bojerg", g.uiFont, g.screenSize[0]/2, menuTextY, colornames.Whitesmoke)
-
-	default:
-		//Draw operation settings & execution
-		uiOp := &ebiten.DrawImageOptions{}
-		boardOp := &ebiten.DrawImageOptions{}
-		bw, bh := g.boardImage.Size()
-
-		uiOp.Filter = Filter
-		boardOp.Filter = Filter
-
-		//factor is used to ensure board fits onto screen nicely at small resolutions
-		factor := g.scaleX
-		if g.scaleX < g.scaleY {
-			factor = g.scaleY
-		}
-		if factor == 0 {
-			factor = 0.01
-		}
-		//store factor in game struct to dynamically change mouse cursor targeting coordinates
-		g.factor = 0.92 / factor
-
-		uiOp.GeoM.Scale(g.factor, g.factor)
-		boardOp.GeoM.Scale(g.factor, g.factor)
-
-		uiOpX := (float64(g.screenSize[0]) - float64(bw)*g.factor) / 2
-		uiOpY := (float64(g.screenSize[1]) - float64(bh)*g.factor) / 2
-		boardOpX := uiOpX
-		boardOpY := uiOpY
-		boardOpRotate := 0.0
-
-		//flipping the board
-		if !g.whitesTurn && g.gameType == 1 {
-			boardOpRotate = math.Pi
-			//bring the board back into view after rotating
-			boardOpX += float64(bw) * g.factor
-			boardOpY += float64(bh) * g.factor
-		}
+		//"Chess by bojerg" is centered as a whole, with the two parts in different font sizes
+		titleBounds := text.BoundString(g.uiFontBig, "Chess")
+		bylineBounds := text.BoundString(g.uiFont, "by bojerg")
+		gap := int(20 * g.layout.Scale)
+		titleX := (w - titleBounds.Dx() - gap - bylineBounds.Dx()) / 2
+		menuTextY := int(g.layout.MenuTitleY)
+		text.Draw(screen, "Chess", g.uiFontBig, titleX, menuTextY, colornames.White)
+		text.Draw(screen, "by bojerg", g.uiFont, titleX+titleBounds.Dx()+gap, menuTextY, colornames.Whitesmoke)
 
-		boardOp.GeoM.Rotate(boardOpRotate)
-		boardOp.GeoM.Translate(boardOpX, boardOpY)
+	case 2:
+		g.DrawEditor(screen)
 
+	default:
 		g.movingImage.Clear()
 		g.DrawHighlightedTiles()
+		g.DrawAnnotations()
 		g.DrawUI()
+		g.DrawAnimations()
 
 		if g.selectedPiece != -1 {
 			g.DrawMovingPiece()
@@ -152,45 +192,81 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			g.scheduleDraw = false
 		}
 
-		screen.DrawImage(g.boardImage, boardOp)
-		screen.DrawImage(g.gameImage, boardOp)
-		screen.DrawImage(g.pieceImage, boardOp)
-		screen.DrawImage(g.uiImage, uiOp)
-		screen.DrawImage(g.movingImage, uiOp)
+		g.DrawBoardLayers(screen)
+		screen.DrawImage(g.uiImage, &ebiten.DrawImageOptions{})
+		screen.DrawImage(g.movingImage, &ebiten.DrawImageOptions{})
 
-		if g.gameOver {
-			text.Draw(screen, g.gameOverMsg, g.uiFont, (g.screenSize[0]/2)-len(g.gameOverMsg)*14, g.screenSize[1]/2+14, colornames.Darkred)
+		// the game over dialog shows the result itself until it is dismissed
+		if g.gameOver && !g.gameUI.Modal() {
+			bounds := text.BoundString(g.uiFont, g.gameOverMsg)
+			text.Draw(screen, g.gameOverMsg, g.uiFont, (w-bounds.Dx())/2, (h+bounds.Dy())/2, colornames.Darkred)
 		}
 	}
 }
 
+// DrawBoardLayers draws the board space layers (the board, highlighted tiles, pieces and annotations) to the Board
+// rect of the screen
+func (g *Game) DrawBoardLayers(screen *ebiten.Image) {
+	//Draw operation settings & execution
+	//The board layers are drawn in board space and scaled to fit the Board rect of the layout
+	boardOp := &ebiten.DrawImageOptions{}
+	boardOp.Filter = Filter
+	boardScale := g.layout.BoardScale()
+	boardOp.GeoM.Scale(boardScale, boardScale)
+
+	//flipping the board
+	if g.BoardFlipped() {
+		boardOp.GeoM.Rotate(math.Pi)
+		//bring the board back into view after rotating
+		boardOp.GeoM.Translate(g.layout.Board.W, g.layout.Board.H)
+	}
+	boardOp.GeoM.Translate(g.layout.Board.X, g.layout.Board.Y)
+
+	screen.DrawImage(g.boardImage, boardOp)
+	screen.DrawImage(g.gameImage, boardOp)
+	screen.DrawImage(g.pieceImage, boardOp)
+	boardOp.ColorM.Scale(1, 1, 1, AnnotationAlpha)
+	screen.DrawImage(g.annotationImage, boardOp)
+}
+
+// ResizeScreenImages keeps uiImage and movingImage the same size as the screen, since they are drawn in screen
+// coordinates
+func (g *Game) ResizeScreenImages(width, height int) {
+	if w, h := g.uiImage.Size(); w == width && h == height {
+		return
+	}
+	g.uiImage.Dispose()
+	g.movingImage.Dispose()
+	g.uiImage = ebiten.NewImage(width, height)
+	g.movingImage = ebiten.NewImage(width, height)
+}
+
 // Update
 // Required function by ebitengine. Contains the logic ran every tick of the game.
 func (g *Game) Update() error {
 	x, y := ebiten.CursorPosition()
 
+	// Any mouse movement or click hands control back from the keyboard
+	if x != g.lastCursor[0] || y != g.lastCursor[1] || ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) ||
+		ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+		g.MouseTakesOver()
+	}
+	g.lastCursor[0], g.lastCursor[1] = x, y
+
 	switch g.gameType {
 	case -1:
 		//at main menu
 
-		//Indicating which controls are hovered over
-		if g.mainMenuButtons[0].PosInBounds(x, y) {
-			g.btnHoverIndex = 1
-		} else {
-			g.btnHoverIndex = -1
-		}
+		g.menuUI.Update()
+		g.UpdateMainMenuKeyboard()
+		g.sounds.UpdateControls()
 
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-			if g.btnHoverIndex != -1 {
-				g.gameType = g.btnHoverIndex
-				g.InitPiecesAndImages()
-				g.btnHoverIndex = -1
-				break
-			}
-		}
+	case 2:
+		g.UpdateEditor()
 
 	default:
 		//playing the game
+		g.UpdateAnimations()
 
 		// Checks for a checkmate if in check (only once per turn)
 		if g.inCheck && g.checkmateNotChecked {
@@ -201,60 +277,40 @@ func (g *Game) Update() error {
 
 		//TODO: Stalemate
 
-		// XY locations reflect the two buttons drawn on screen
-		// This code block determines what the mouse is interacting with and updates the appropriate parameter
-		// Factor is utilized here to match up the scaled down render with our "scaled down" mouse XY coordinates
-		x := int(float64(x) / g.factor)
-		y := int(float64(y) / g.factor)
+		// The layout holds the rects that were drawn, so the mouse is tested against exactly what is on screen
+		boardRow, boardCol, onBoard := g.layout.SquareAt(x, y)
 
-		edgeX := (float64(g.screenSize[0]) - (1024 * g.factor)) / 2
-		edgeY := (float64(g.screenSize[1]) - (1024 * g.factor)) / 2
-		tile := TileSize * g.factor
+		// the widgets get the mouse first, and the board only gets what they leave alone
+		g.pointerOnUI = g.gameUI.Update()
+		if g.gameType == -1 {
+			// the Main Menu button was clicked
+			break
+		}
 
-		if g.inGameButtons[0].PosInBounds(x, y) {
-			g.btnHoverIndex = 1
-		} else if g.inGameButtons[1].PosInBounds(x, y) {
-			g.btnHoverIndex = 2
-		} else {
-			g.btnHoverIndex = -1
-			// fancy min max floor math to determine the closest board square to the cursor, even
-			// when the mouse is not over the board
-			g.selectedCol = int(math.Floor(math.Min(math.Max(((float64(x)*g.factor)-edgeX)/tile, 0), 7)))
-			g.selectedRow = int(math.Floor(math.Min(math.Max(((float64(y)*g.factor)-edgeY)/tile, 0), 7)))
+		if !g.keyboardMode && !g.pointerOnUI {
+			// the closest board square to the cursor, even when the mouse is not over the board
+			g.selectedRow, g.selectedCol = boardRow, boardCol
 
 			// invert selected row and col when the board is rotated
-			if !g.whitesTurn && g.gameType == 1 {
+			if g.BoardFlipped() {
 				g.selectedCol = (g.selectedCol - 7) * -1
 				g.selectedRow = (g.selectedRow - 7) * -1
 			}
 		}
 
-		// left click hold and drag
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-
-			if g.btnHoverIndex != -1 {
-
-				if g.btnHoverIndex == 1 {
-					//Return to menu
-					//set game type to menu
-					g.gameType = -1
-
-				} else if g.btnHoverIndex == 2 {
-					//Start new game of same type
-					//reset game variables and images
-					g.InitPiecesAndImages()
-				}
+		g.UpdateAnnotations(onBoard && !g.pointerOnUI)
 
-			} else {
-				//Not clicking on a button...
+		// left click hold and drag, carrying on with a dragged piece even if it passes over a widget
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 
-				if g.selectedPiece == -1 {
+			if !g.pointerOnUI || g.selectedPiece != -1 {
+				if g.selectedPiece == -1 && !g.gameOver {
 					// No piece selected but left mouse is held down
 					// Match the selected tile to a piece location. Then, ensure the piece belongs to the
 					// team whose turn it currently is, and that it is still in play.
 					for i, piece := range g.pieces {
 						if piece.Col() == g.selectedCol && piece.Row() == g.selectedRow {
-							if piece.Col() != -1 && g.whitesTurn == piece.White() {
+							if g.whitesTurn == piece.White() {
 								g.selectedPiece = i
 								// store the xy coordinates of the cursor
 								g.selectedLocation[0] = float64(x)
@@ -273,13 +329,13 @@ func (g *Game) Update() error {
 			}
 		} else { // MouseButtonLeft is not pressed
 
-			//If we do have a piece selected
-			if g.selectedPiece != -1 {
+			//If we do have a piece selected, and it isn't being carried by the keyboard
+			if g.selectedPiece != -1 && !g.keyboardHeld {
 
 				// piece is asking to be let go of at it the current mouse position
 				// Verify the move if the piece is being set down on a different square than it started on
 				if g.pieces[g.selectedPiece].Col() != g.selectedCol || g.pieces[g.selectedPiece].Row() != g.selectedRow {
-					g.MakeMoveIfLegal(g.selectedRow, g.selectedCol)
+					g.DropPiece(g.pieces[g.selectedPiece], g.selectedRow, g.selectedCol)
 				}
 
 				//Either way, we need to update the board image and clear selectedPiece index
@@ -287,113 +343,96 @@ func (g *Game) Update() error {
 				g.selectedPiece = -1
 			}
 		}
+
+		g.UpdateKeyboard()
+		// typed moves shouldn't change the sound settings
+		if !g.gameUI.Typing() {
+			g.sounds.UpdateControls()
+		}
 	}
 
 	return nil
 }
 
+// SquareCenter returns the center of a board square in screen coordinates, which is the space used by
+// selectedLocation and the in-game buttons
+func (g *Game) SquareCenter(row, col int) (float64, float64) {
+	if g.BoardFlipped() {
+		row = 7 - row
+		col = 7 - col
+	}
+	tile := g.layout.Board.W / 8
+	return g.layout.Board.X + (float64(col)+0.5)*tile, g.layout.Board.Y + (float64(row)+0.5)*tile
+}
+
 // MakeMoveIfLegal handles three things: Checking if a move is legal, removing a taken piece from the
-// game if the move was legal, and handling the switching of turns.
-func (g *Game) MakeMoveIfLegal(row, col int) {
+// game if the move was legal, and handling the switching of turns. Returns true if the move was made.
+// Only the From, To and Promotion of m are used, the rest is taken from the matching move in the piece's Moves().
+func (g *Game) MakeMoveIfLegal(m Move) bool {
 	//check if move is legal
-	//first, make sure the tile it's being set on is possible by comparing it to the Piece's GetMoves function
+	//first, make sure the move is possible by finding it in the Piece's Moves function
 	//second, don't allow the player to put themselves into check, and see if they are putting their opponent in check
-	possibleMoves := g.pieces[g.selectedPiece].Moves(*g)
+	mover := g.PieceOn(m.From[0], m.From[1])
 	legal := false
 
-	for _, move := range possibleMoves {
-		if move[0] == row && move[1] == col {
-			//we found the move in list of possible moves
-			legal = true
-			break
+	if mover != nil && mover.White() == g.whitesTurn {
+		for _, move := range mover.Moves(*g) {
+			if move.Matches(m) {
+				//we found the move in list of possible moves, which knows what kind of move it is
+				m = move
+				legal = true
+				break
+			}
 		}
 	}
+	row, col := m.To[0], m.To[1]
 
 	if legal {
-		//we should save the old piece position then set the new position and make sure the move is still legal
-		startingPos := [2]int{g.pieces[g.selectedPiece].Row(), g.pieces[g.selectedPiece].Col()}
-		g.pieces[g.selectedPiece].SetRow(g.selectedRow)
-		g.pieces[g.selectedPiece].SetCol(g.selectedCol)
+		//we should save the old piece position so it can be put back if the move turns out to be illegal
+		startingPos := m.From
 
-		//determining castle move
-		isCastle := false
+		isCastle := m.Is(FlagCastle)
 		//need to also move the rook if this is a castle move
-		var castleRookIndex int
+		var castleRook ChessPiece
 		var castleRookStartPos [2]int
 		//piece.Moves() already determined that this move was legal checking prior piece moves, but we need to check
 		//the other special rules that dictate legal castle moves. See https://www.chess.com/article/view/how-to-castle-in-chess
-		if IsKing(g.pieces[g.selectedPiece]) {
-
-			//A legal king move of more than one space can only be a castle move
-			moveDistance := startingPos[1] - g.pieces[g.selectedPiece].Col()
-			isCastle = moveDistance == -2 || moveDistance == 2
-			if isCastle {
-				if moveDistance == 2 {
-					//queen side castle
-					if g.whitesTurn {
-						//rook 7,0
-						castleRookStartPos[0] = 7
-						castleRookStartPos[1] = 0
-					} else {
-						//rook 0,0
-						castleRookStartPos[0] = 0
-						castleRookStartPos[1] = 0
-					}
-				} else if moveDistance == -2 {
-					//king side castle
-					if g.whitesTurn {
-						//rook 7,7
-						castleRookStartPos[0] = 7
-						castleRookStartPos[1] = 7
-					} else {
-						//rook 0,7
-						castleRookStartPos[0] = 0
-						castleRookStartPos[1] = 7
-					}
-				}
-				for i, p := range g.pieces {
-					if p.White() == g.whitesTurn && p.Row() == castleRookStartPos[0] && p.Col() == castleRookStartPos[1] {
-						castleRookIndex = i
-						break
-					}
-				}
+		if isCastle {
+			if startingPos[1]-col == 2 {
+				//queen side castle, rook 7,0 or 0,0
+				castleRookStartPos = [2]int{startingPos[0], 0}
+			} else {
+				//king side castle, rook 7,7 or 0,7
+				castleRookStartPos = [2]int{startingPos[0], 7}
+			}
+			castleRook = g.PieceOn(castleRookStartPos[0], castleRookStartPos[1])
+			if castleRook == nil || !IsRook(castleRook) || castleRook.White() != mover.White() {
+				//there is no rook to castle with
+				g.emit(EventIllegalMove)
+				return false
 			}
 		}
 
 		//Need these in scope for later code block. Used to store... you guessed it!
-		var capturedPiece *ChessPiece
-		capturedOldCol := -1
+		capturedPiece := m.Captured
+		capturedIndex := -1
+		var promoted ChessPiece
 
 		//normal procedure to prepare to simulate non-castle moves
 		if !isCastle {
-			//Is this move an en passant?
-			//modifying which row we search for in the following loop to match piece being taken en passant
-			enPassant := false
-			modifiedRow := row
-			if IsPawn(g.pieces[g.selectedPiece]) {
-
-				if g.pieces[g.selectedPiece].White() && startingPos[0] == 3 {
-					enPassant = g.enPassantLocation[0] == row+1 && g.enPassantLocation[1] == col
-				} else if startingPos[0] == 4 {
-					enPassant = g.enPassantLocation[0] == row-1 && g.enPassantLocation[1] == col
-				}
-
-				if enPassant {
-					modifiedRow = g.enPassantLocation[0]
-				}
+			// If the move takes a piece, we need to take it away! The piece taken en passant isn't on the square
+			// we are moving to, but the move knows where it is.
+			if capturedPiece != nil {
+				capturedIndex = g.PieceIndex(capturedPiece)
+				g.CapturePiece(capturedIndex)
 			}
+			g.MovePiece(mover, row, col)
 
-			// If there's a piece on the square we moved to, we need to take it away!
-			for i, piece := range g.pieces {
-				//modifiedRow is to allow EN PASSANT see above code block/s
-				if piece.Row() == modifiedRow && piece.Col() == col {
-					if i != g.selectedPiece {
-						capturedOldCol = piece.Col()
-						piece.SetCol(-1) // Col of -1 is de facto notation for piece taken
-						capturedPiece = &piece
-						break
-					}
-				}
+			// a promoting pawn is swapped for its new piece
+			if m.Is(FlagPromotion) {
+				promoted = NewPiece(m.Promotion, row, col, mover.White())
+				g.pieces[g.PieceIndex(mover)] = promoted
+				g.board[row][col] = promoted
 			}
 		} else { //CASTLE
 
@@ -403,108 +442,74 @@ func (g *Game) MakeMoveIfLegal(row, col int) {
 				skippedSpaceDir = 1
 			}
 
-			//move king to appropriate "skipped space"
-			g.pieces[g.selectedPiece].SetCol(g.pieces[g.selectedPiece].Col() + skippedSpaceDir)
-
-			// for each piece on opposing team, does it have possible move to check this player after the move?
-			// reminder, a piece with col of -1 has been taken
-			for _, piece := range g.pieces {
-				if piece.White() != g.whitesTurn && piece.Col() != -1 {
-
-					//check possible moves for each valid piece and see if any would check the king
-					for _, move := range piece.Moves(*g) {
-						otherPiece := GetPieceOnSquare(move[0], move[1], g.pieces)
-						if otherPiece != nil && otherPiece.White() == g.whitesTurn && IsKing(otherPiece) == true {
-							legal = false
-							break
-						}
-					}
-				}
-				if !legal {
-					break
-				}
+			//move king to appropriate "skipped space" and see if the other side could take it there
+			g.MovePiece(mover, row, col+skippedSpaceDir)
+			if g.KingInCheck(g.whitesTurn) {
+				legal = false
 			}
 
-			//move king back to it's ending location and move the rook appropriately
-			g.pieces[g.selectedPiece].SetCol(g.pieces[g.selectedPiece].Col() - skippedSpaceDir)
-			g.pieces[castleRookIndex].SetCol(g.pieces[g.selectedPiece].Col() + skippedSpaceDir)
-
+			//move king to it's ending location and move the rook appropriately
+			g.MovePiece(mover, row, col)
+			g.MovePiece(castleRook, row, col+skippedSpaceDir)
 		}
 
-		// for each piece on opposing team, does it have possible move to check this player after the move?
-		// reminder, a piece with col of -1 has been taken
-		for _, piece := range g.pieces {
-			if piece.White() != g.whitesTurn && piece.Col() != -1 {
-
-				//check possible moves for each valid piece and see if any would check the king
-				for _, move := range piece.Moves(*g) {
-					otherPiece := GetPieceOnSquare(move[0], move[1], g.pieces)
-					if otherPiece != nil && otherPiece.White() == g.whitesTurn && IsKing(otherPiece) == true {
-						legal = false
-						break
-					}
-				}
-			}
-			// idk but it's probably slightly faster to break the loop as soon as move is shown to be illegal
-			// honestly it just bothers me to break the inner loop but continue on the outer loop for no reason
-			if !legal {
-				break
-			}
+		// does any piece on the opposing team have a possible move to check this player after the move?
+		if g.KingInCheck(g.whitesTurn) {
+			legal = false
 		}
 
 		//Here, we have finished our move evaluation. Either put it back and allow player to try another move,
 		//or let the legal move play and switch teams, etc.
 		if !legal {
-			//put the piece back
-			g.pieces[g.selectedPiece].SetRow(startingPos[0])
-			g.pieces[g.selectedPiece].SetCol(startingPos[1])
+			//put the piece back, as a pawn if it had promoted
+			if promoted != nil {
+				g.pieces[g.PieceIndex(promoted)] = mover
+				g.board[row][col] = mover
+			}
+			g.MovePiece(mover, startingPos[0], startingPos[1])
 
 			//put the captured piece back too, if it was taken
 			if capturedPiece != nil {
-				(*capturedPiece).SetCol(capturedOldCol)
+				g.RestorePiece(capturedIndex)
 			}
 
 			if isCastle {
-				g.pieces[castleRookIndex].SetRow(castleRookStartPos[0])
-				g.pieces[castleRookIndex].SetCol(castleRookStartPos[1])
+				g.MovePiece(castleRook, castleRookStartPos[0], castleRookStartPos[1])
 			}
 
 		} else {
 
-			//ugly block of code to facilitate legal en passant moves next turn
-			ThisPawnCanBeEnPassant := false
-			if IsPawn(g.pieces[g.selectedPiece]) {
-				if g.pieces[g.selectedPiece].White() {
-					ThisPawnCanBeEnPassant = startingPos[0] == 6 && g.pieces[g.selectedPiece].Row() == 4
-				} else {
-					ThisPawnCanBeEnPassant = startingPos[0] == 1 && g.pieces[g.selectedPiece].Row() == 3
-				}
-			}
-			if ThisPawnCanBeEnPassant {
-				g.enPassantLocation[0] = g.pieces[g.selectedPiece].Row()
-				g.enPassantLocation[1] = g.pieces[g.selectedPiece].Col()
+			//a pawn that moved two squares can be taken en passant next turn
+			if m.Is(FlagDoublePush) {
+				g.enPassantLocation[0] = mover.Row()
+				g.enPassantLocation[1] = mover.Col()
 			} else {
 				g.enPassantLocation[0] = -1
 				g.enPassantLocation[1] = -1
 			}
 
-			g.inCheck = false
+			g.lastMove = [2][2]int{startingPos, {row, col}}
+			if capturedPiece != nil || IsPawn(mover) {
+				g.halfmoveClock = 0
+			} else {
+				g.halfmoveClock++
+			}
 			g.checkmateNotChecked = true
 			g.moveNum++
 			g.whitesTurn = !g.whitesTurn //switch turns
 
 			//if king moved, remove right to any castle moves
 			//if rook moved, remove it's right to be a part of a castle
-			if IsKing(g.pieces[g.selectedPiece]) {
-				if g.pieces[g.selectedPiece].White() {
+			if IsKing(mover) {
+				if mover.White() {
 					g.whiteCastles[0] = false
 					g.whiteCastles[1] = false
 				} else {
 					g.blackCastles[0] = false
 					g.blackCastles[1] = false
 				}
-			} else if IsRook(g.pieces[g.selectedPiece]) {
-				if g.pieces[g.selectedPiece].White() {
+			} else if IsRook(mover) {
+				if mover.White() {
 					//ensure this castle was available, and our piece was still on starting tile
 					if g.whiteCastles[0] && startingPos[0] == 7 && startingPos[1] == 0 {
 						g.whiteCastles[0] = false
@@ -522,127 +527,120 @@ func (g *Game) MakeMoveIfLegal(row, col int) {
 				}
 			}
 
-			//now checking if this move puts the opponent in check
-			//note we switched turns in the logic just before this loop
-			for _, piece := range g.pieces {
-				if piece.White() != g.whitesTurn && piece.Col() != -1 {
-
-					//check possible moves for each valid piece and see if any would check the king
-					for _, move := range piece.Moves(*g) {
-						otherPiece := GetPieceOnSquare(move[0], move[1], g.pieces)
-						if otherPiece != nil && otherPiece.White() == g.whitesTurn && IsKing(otherPiece) == true {
-							g.inCheck = true
-							break
-						}
-					}
+			//a rook taken on its starting tile can't castle anymore either
+			if capturedPiece != nil && IsRook(capturedPiece) {
+				castles, homeRow := &g.whiteCastles, 7
+				if !capturedPiece.White() {
+					castles, homeRow = &g.blackCastles, 0
+				}
+				if capturedPiece.Row() == homeRow && capturedPiece.Col() == 0 {
+					castles[0] = false
+				} else if capturedPiece.Row() == homeRow && capturedPiece.Col() == 7 {
+					castles[1] = false
 				}
 			}
 
+			//now checking if this move puts the opponent in check
+			//note we switched turns just before this
+			g.inCheck = g.KingInCheck(g.whitesTurn)
+
+			//let anyone listening know what kind of move was made, most notable first
+			switch {
+			case g.inCheck:
+				g.emit(EventCheck)
+			case isCastle:
+				g.emit(EventCastle)
+			case promoted != nil:
+				g.emit(EventPromotion)
+			case capturedPiece != nil:
+				g.emit(EventCapture)
+			default:
+				g.emit(EventMove)
+			}
 		}
 	}
-}
-
-func (g *Game) IsCheckmate() {
-	checkmate := true
-	// Try every possible move and see if still in check
-	for _, piece := range g.pieces {
-		if piece.White() == g.whitesTurn && piece.Col() != -1 {
 
-			//save the original position so we can put the piece back after checking moves
-			startingPos := [2]int{piece.Row(), piece.Col()}
-
-			//capturedPiece is a placeholder to save pieces that are taken by potential moves
-			//we should put it back after running our check
-			var capturedPiece *ChessPiece
-			capturedOldCol := -1
-
-			for _, move := range piece.Moves(*g) {
-
-				//here we simulate each move and see if it gets them out of check
-				//if it does, we put the pieces back and exit the loop, indicating it's not checkmate
-				//otherwise, we'll put the pieces back and try the next move
+	if !legal {
+		g.emit(EventIllegalMove)
+	}
 
-				//simulate move
-				otherPiece := GetPieceOnSquare(move[0], move[1], g.pieces)
-				if otherPiece != nil && otherPiece.White() != g.whitesTurn {
-					capturedOldCol = otherPiece.Col()
-					otherPiece.SetCol(-1)
-					capturedPiece = &otherPiece
-				}
-				piece.SetRow(move[0])
-				piece.SetCol(move[1])
-
-				//check if this move still leaves them in check
-				thisMoveInCheck := false
-				for _, nestedPiece := range g.pieces {
-					if nestedPiece.White() != g.whitesTurn && nestedPiece.Col() != -1 {
-						//check possible moves for each valid piece and see if any would check the king
-						for _, nestedMove := range nestedPiece.Moves(*g) {
-							otherOtherPiece := GetPieceOnSquare(nestedMove[0], nestedMove[1], g.pieces)
-							if otherOtherPiece != nil && otherOtherPiece.White() == g.whitesTurn && IsKing(otherOtherPiece) == true {
-								thisMoveInCheck = true
-								break
-							}
-						}
-						//break out of the upper loop too if check was found for this move
-						if thisMoveInCheck {
-							break
-						}
-					}
-				}
+	return legal
+}
 
-				//put our pieces back
-				piece.SetRow(startingPos[0])
-				piece.SetCol(startingPos[1])
+// LegalMoves filters pieces[index].Moves() down to the moves MakeMoveIfLegal would accept, ie. those that don't
+// leave the player's own king in check or castle through check.
+func (g *Game) LegalMoves(index int) []Move {
+	legalMoves := make([]Move, 0)
+	for _, move := range g.pieces[index].Moves(*g) {
+		if g.IsLegalMove(move) {
+			legalMoves = append(legalMoves, move)
+		}
+	}
+	return legalMoves
+}
 
-				if capturedPiece != nil {
-					(*capturedPiece).SetCol(capturedOldCol)
-				}
+// IsLegalMove reports whether MakeMoveIfLegal would accept m. The move is tried on a copy of the game with copies of
+// the pieces, so nothing here changes and no events are emitted.
+func (g *Game) IsLegalMove(m Move) bool {
+	scratch := *g
+	scratch.eventHandlers = nil
+	pieces := make([]ChessPiece, len(g.pieces))
+	for i, piece := range g.pieces {
+		pieces[i] = ClonePiece(piece)
+	}
+	scratch.SetPieces(pieces)
+	return scratch.MakeMoveIfLegal(m)
+}
 
-				//if true, we found a move that gets the player out of check
-				if !thisMoveInCheck {
-					checkmate = false
-					break
+// KingInCheck reports whether the white (or black) king is attacked by any piece of the other side, whoever's move
+// it is
+func (g *Game) KingInCheck(white bool) bool {
+	for _, piece := range g.pieces {
+		if piece.White() != white {
+			for _, move := range piece.Moves(*g) {
+				if move.Captured != nil && move.Captured.White() == white && IsKing(move.Captured) {
+					return true
 				}
 			}
 		}
-		if !checkmate {
-			break
-		}
 	}
-	if checkmate {
-		g.gameOver = true
-		g.gameOverMsg = "Checkmate, "
-		if g.whitesTurn {
-			g.gameOverMsg += "Black wins!"
-		} else {
-			g.gameOverMsg += "White wins!"
+	return false
+}
+
+// IsCheckmate ends the game if the side to move, which is in check, has no legal move to get out of it
+func (g *Game) IsCheckmate() {
+	for i, piece := range g.pieces {
+		if piece.White() == g.whitesTurn && len(g.LegalMoves(i)) > 0 {
+			return
 		}
 	}
+	g.EndGame(WinFor(!g.whitesTurn, TerminationCheckmate))
 }
 
 func (g *Game) DrawStaticPieces() {
 	g.pieceImage.Clear()
 
-	xOffset := 465.0
-	yOffset := 42.0
+	// pieces are centered in their square (in board space)
+	offset := (TileSize - PieceImageSize*PieceScale) / 2
 	rotate := 0.0
 
-	// Rotate the board for local multiplayer (gameType 1)
-	if !g.whitesTurn && g.gameType == 1 {
+	// Keep pieces upright when the board is flipped
+	if g.BoardFlipped() {
 		rotate = math.Pi
-		xOffset += TileSize - 34
-		yOffset += TileSize - 28
 	}
 
 	for i, piece := range g.pieces {
-		// Don't draw selected (moving) piece, or any pieces with id of 6 (taken)
-		if i != g.selectedPiece && piece.Col() != -1 {
-			tx := float64(g.pieces[i].Col()*TileSize) + xOffset
-			ty := float64(g.pieces[i].Row()*TileSize) + yOffset
+		// Don't draw selected (moving) piece or pieces being animated
+		if i != g.selectedPiece && !g.IsSliding(piece) {
+			tx := float64(g.pieces[i].Col()*TileSize) + offset
+			ty := float64(g.pieces[i].Row()*TileSize) + offset
 			opPiece := &ebiten.DrawImageOptions{}
-			opPiece.GeoM.Rotate(rotate)
-			opPiece.GeoM.Scale(1.5, 1.5) //essentially W x H = 90 x 90
+			opPiece.GeoM.Scale(PieceScale, PieceScale)
+			if rotate != 0 {
+				// rotating about the top left corner moves the piece up and left by its own size
+				opPiece.GeoM.Rotate(rotate)
+				opPiece.GeoM.Translate(PieceImageSize*PieceScale, PieceImageSize*PieceScale)
+			}
 			opPiece.GeoM.Translate(tx, ty)
 			opPiece.Filter = Filter
 			g.pieceImage.DrawImage(g.pieces[i].Image(), opPiece)
@@ -653,10 +651,12 @@ func (g *Game) DrawStaticPieces() {
 func (g *Game) DrawMovingPiece() {
 	for i, _ := range g.pieces {
 		if i == g.selectedPiece {
-			tx := g.selectedLocation[0] - 45
-			ty := g.selectedLocation[1] - 45
+			// the same size as the pieces on the board
+			scale := PieceScale * g.layout.BoardScale()
+			tx := g.selectedLocation[0] - PieceImageSize*scale/2
+			ty := g.selectedLocation[1] - PieceImageSize*scale/2
 			opPiece := &ebiten.DrawImageOptions{}
-			opPiece.GeoM.Scale(1.5, 1.5) //essentially W x H = 90 x 90
+			opPiece.GeoM.Scale(scale, scale)
 			opPiece.GeoM.Translate(tx, ty)
 			opPiece.Filter = Filter
 			g.movingImage.DrawImage(g.pieces[i].Image(), opPiece)
@@ -669,27 +669,24 @@ func (g *Game) DrawHighlightedTiles() {
 	tileImage := ebiten.NewImage(TileSize, TileSize)
 	g.gameImage.Clear()
 
-	// drawing highlighted tiles (available moves in red)
-	if g.selectedPiece >= 0 {
-		availableMoves := g.pieces[g.selectedPiece].Moves(*g)
-		if availableMoves != nil {
-			for _, move := range availableMoves {
-				opTile := &ebiten.DrawImageOptions{}
-				opTile.GeoM.Translate(float64(move[1]*TileSize+448), float64(move[0]*TileSize+28))
-				tileImage.Fill(color.RGBA{R: 0xff, G: 0x06, B: 0x03, A: 0xba})
-				g.gameImage.DrawImage(tileImage, opTile)
-			}
+	// the previous move's from and to squares stay highlighted (in olive) until the next move
+	if g.lastMove[0][0] != -1 {
+		tileImage.Fill(color.NRGBA{R: 0xaa, G: 0xb0, B: 0x3a, A: 0xb0})
+		for _, square := range g.lastMove {
+			opTile := &ebiten.DrawImageOptions{}
+			opTile.GeoM.Translate(float64(square[1]*TileSize), float64(square[0]*TileSize))
+			g.gameImage.DrawImage(tileImage, opTile)
 		}
-
 	}
 
-	// Draw hovered tile (in highlighter yellow) if not hovering a button
-	if g.btnHoverIndex == -1 {
+	// Draw hovered tile (in highlighter yellow) if the cursor isn't on a widget, or while using the keyboard, if
+	// the board has focus
+	if (g.keyboardMode && g.gameUI.Focused() == nil) || (!g.keyboardMode && !g.pointerOnUI) {
 		for r := 0; r < 8; r++ {
 			for c := 0; c < 8; c++ {
 				if r == g.selectedRow && c == g.selectedCol {
 					opTile := &ebiten.DrawImageOptions{}
-					opTile.GeoM.Translate(float64(c*TileSize+448), float64(r*TileSize+28))
+					opTile.GeoM.Translate(float64(c*TileSize), float64(r*TileSize))
 					tileImage.Fill(color.RGBA{R: 0xea, G: 0xdd, B: 0x23, A: 0xff})
 					g.gameImage.DrawImage(tileImage, opTile)
 					break
@@ -703,7 +700,7 @@ func (g *Game) DrawHighlightedTiles() {
 		for _, piece := range g.pieces {
 			if IsKing(piece) && piece.White() == g.whitesTurn {
 				opTile := &ebiten.DrawImageOptions{}
-				opTile.GeoM.Translate(float64(piece.Col()*TileSize+448), float64(piece.Row()*TileSize+28))
+				opTile.GeoM.Translate(float64(piece.Col()*TileSize), float64(piece.Row()*TileSize))
 				tileImage.Fill(color.RGBA{R: 0xbf, G: 0x00, B: 0xe6, A: 0xff})
 				g.gameImage.DrawImage(tileImage, opTile)
 				break
@@ -711,26 +708,45 @@ func (g *Game) DrawHighlightedTiles() {
 		}
 	}
 
+	// legal moves of the selected piece: a dot on empty squares, and a ring around pieces that can be taken
+	if g.selectedPiece >= 0 {
+		for _, move := range g.LegalMoves(g.selectedPiece) {
+			var path vector.Path
+			x, y := BoardSquareCenter(move.To[0], move.To[1])
+			// en passant is a capture too, even though the square is empty
+			if move.Is(FlagCapture) {
+				path.MoveTo(x+CaptureRingRadius, y)
+				path.Arc(x, y, CaptureRingRadius, 0, 2*math.Pi, vector.Clockwise)
+				path.MoveTo(x+CaptureRingRadius-CaptureRingThickness, y)
+				path.Arc(x, y, CaptureRingRadius-CaptureRingThickness, 0, 2*math.Pi, vector.CounterClockwise)
+			} else {
+				path.MoveTo(x+MoveDotRadius, y)
+				path.Arc(x, y, MoveDotRadius, 0, 2*math.Pi, vector.Clockwise)
+			}
+			FillPath(g.gameImage, &path, color.NRGBA{R: 0x1b, G: 0x1b, B: 0x1b, A: 0x70})
+		}
+	}
 }
 
+var (
+	darkSquareColor  = color.RGBA{R: 0xbb, G: 0x99, B: 0x55, A: 0xff}
+	lightSquareColor = color.RGBA{R: 0xcb, G: 0xbe, B: 0xb5, A: 0xff}
+)
+
 func (g *Game) DrawBoard() {
-	darkColor := color.RGBA{R: 0xbb, G: 0x99, B: 0x55, A: 0xff}
-	lightColor := color.RGBA{R: 0xcb, G: 0xbe, B: 0xb5, A: 0xff}
 
-	lightImage := ebiten.NewImage(TileSize*8, TileSize*8)
+	lightImage := ebiten.NewImage(BoardSize, BoardSize)
 	darkImage := ebiten.NewImage(TileSize, TileSize)
 
 	// Drawing one big light square to (slightly) cut down on draw ops
-	opLight := &ebiten.DrawImageOptions{}
-	opLight.GeoM.Translate(448, 28)
-	lightImage.Fill(lightColor)
-	g.boardImage.DrawImage(lightImage, opLight)
+	lightImage.Fill(lightSquareColor)
+	g.boardImage.DrawImage(lightImage, &ebiten.DrawImageOptions{})
 	for row := 0; row < 8; row++ {
 		for col := 0; col < 8; col++ {
 			if (row%2 == 0 && col%2 != 0) || (row%2 != 0 && col%2 == 0) {
 				opDark := &ebiten.DrawImageOptions{}
-				opDark.GeoM.Translate(float64(col*TileSize+448), float64(row*TileSize+28))
-				darkImage.Fill(darkColor)
+				opDark.GeoM.Translate(float64(col*TileSize), float64(row*TileSize))
+				darkImage.Fill(darkSquareColor)
 				g.boardImage.DrawImage(darkImage, opDark)
 			}
 
@@ -744,13 +760,11 @@ func (g *Game) DrawUI() {
 	//Arranging taken pieces into two structs to sort by value and team for display
 	var whitePieces []ChessPiece
 	var blackPieces []ChessPiece
-	for _, piece := range g.pieces {
-		if piece.Col() == -1 {
-			if piece.White() {
-				whitePieces = append(whitePieces, piece)
-			} else {
-				blackPieces = append(blackPieces, piece)
-			}
+	for _, piece := range g.captured {
+		if piece.White() {
+			whitePieces = append(whitePieces, piece)
+		} else {
+			blackPieces = append(blackPieces, piece)
 		}
 	}
 
@@ -762,77 +776,43 @@ func (g *Game) DrawUI() {
 		return GetWeighting(blackPieces[p]) < GetWeighting(blackPieces[q])
 	})
 
-	//The following offsets and modifiers help to dynamically grow the column of taken pieces and flip them
-	//as the board is flipped
-	boardSize := 1024 * g.factor
-	offset := 60 * g.factor
-	whiteXOffset := ((float64(g.screenSize[0]) - boardSize) / 2) - offset
-	blackXOffset := ((float64(g.screenSize[0]) - boardSize) / 2) + boardSize + offset
-	whiteYOffset := ((float64(g.screenSize[1]) - boardSize) / 2) + offset
-	blackYOffset := ((float64(g.screenSize[1]) - boardSize) / 2) + boardSize - offset
-	whiteGrowth := -24.0
-	blackGrowth := 24.0
-
-	whiteXOffset *= 1 / g.factor
-	whiteYOffset *= 1 / g.factor
-	blackXOffset *= 1 / g.factor
-	blackYOffset *= 1 / g.factor
-
-	// Rotate the board for local multiplayer (gameType 1)
-	if !g.whitesTurn && g.gameType == 1 {
-		tmpX := whiteXOffset
-		tmpY := whiteYOffset
-		whiteXOffset = blackXOffset
-		whiteYOffset = blackYOffset
-		blackXOffset = tmpX
-		blackYOffset = tmpY
-		whiteGrowth *= -1
-		blackGrowth *= -1
+	//White's taken pieces run along the top left of the board and black's along the bottom right (or above and
+	//below the board in portrait), swapping over as the board is flipped
+	whiteRow, blackRow := g.layout.TakenRows[0], g.layout.TakenRows[1]
+	if g.BoardFlipped() {
+		whiteRow, blackRow = blackRow, whiteRow
 	}
 
 	//Draw the lists of taken piece images
 	for i, p := range whitePieces {
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(len(whitePieces)-i)*whiteGrowth+whiteXOffset, whiteYOffset)
-		op.Filter = Filter
-		g.uiImage.DrawImage(p.Image(), op)
+		g.DrawTakenPiece(p, float64(len(whitePieces)-i)*whiteRow.Step+whiteRow.X, whiteRow.Y)
 	}
 	//
 	for i, p := range blackPieces {
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(len(blackPieces)-i)*blackGrowth+blackXOffset, blackYOffset)
-		op.Filter = Filter
-		g.uiImage.DrawImage(p.Image(), op)
+		g.DrawTakenPiece(p, float64(len(blackPieces)-i)*blackRow.Step+blackRow.X, blackRow.Y)
 	}
 
-	btnX := int(float64(g.screenSize[0]) * 0.1)
-	g.inGameButtons[0].x = btnX
-	g.inGameButtons[0].y = int((float64(g.screenSize[1])/g.factor)/2) - BtnHeight - 7
-	g.inGameButtons[1].x = btnX
-	g.inGameButtons[1].y = int((float64(g.screenSize[1])/g.factor)/2) + 7
-
-	opMenuBtn1 := &ebiten.DrawImageOptions{}
-	opMenuBtn1.GeoM.Translate(float64(g.inGameButtons[0].x), float64(g.inGameButtons[0].y))
-	opMenuBtn1.Filter = Filter
-	if g.btnHoverIndex == 1 {
-		g.uiImage.DrawImage(g.btnPrimaryHover, opMenuBtn1)
-		text.Draw(g.uiImage, g.inGameButtons[0].text, g.uiFontSmall, g.inGameButtons[0].TextX(), g.inGameButtons[0].TextY(), colornames.Gray)
-	} else {
-		g.uiImage.DrawImage(g.btnPrimary, opMenuBtn1)
-		text.Draw(g.uiImage, g.inGameButtons[0].text, g.uiFontSmall, g.inGameButtons[0].TextX(), g.inGameButtons[0].TextY(), colornames.Whitesmoke)
+	g.DrawCoordinates()
+	g.DrawMoveInput()
+
+	text.Draw(g.uiImage, g.sounds.Status(), g.uiFontSmall, int(g.layout.Buttons[2].X), int(g.layout.StatusY), colornames.Gray)
+
+	openingY := g.layout.OpeningY
+	for _, line := range wrapText(g.opening.String(), 28) {
+		text.Draw(g.uiImage, line, g.uiFontSmall, int(g.layout.OpeningX), int(openingY), colornames.Whitesmoke)
+		openingY += 22 * g.layout.Scale
 	}
 
-	opMenuBtn2 := &ebiten.DrawImageOptions{}
-	opMenuBtn2.GeoM.Translate(float64(g.inGameButtons[1].x), float64(g.inGameButtons[1].y))
-	opMenuBtn2.Filter = Filter
-	if g.btnHoverIndex == 2 {
-		g.uiImage.DrawImage(g.btnInfoHover, opMenuBtn2)
-		text.Draw(g.uiImage, g.inGameButtons[1].text, g.uiFontSmall, g.inGameButtons[1].TextX(), g.inGameButtons[1].TextY(), colornames.Gray)
+	if g.CanAbort() {
+		g.resignButton.text = "Abort"
 	} else {
-		g.uiImage.DrawImage(g.btnInfo, opMenuBtn2)
-		text.Draw(g.uiImage, g.inGameButtons[1].text, g.uiFontSmall, g.inGameButtons[1].TextX(), g.inGameButtons[1].TextY(), colornames.Whitesmoke)
+		g.resignButton.text = "Resign"
 	}
+	g.resignButton.SetEnabled(!g.gameOver)
+	g.drawButton.SetEnabled(!g.gameOver && !g.CanAbort())
 
+	//widgets last, so open dialogs cover everything else
+	g.gameUI.Draw(g.uiImage)
 }
 
 func (g *Game) DrawMainMenu(generate bool) {
@@ -841,16 +821,18 @@ func (g *Game) DrawMainMenu(generate bool) {
 	//Generate the image once to significantly improve performance and thus appearance
 	if generate {
 		g.selectedCol = 0 //reset this because we use it as a counter for scrolling effect
-		g.pieces[0] = &Pawn{Piece{0, 0, false}}
-		g.pieces[1] = &Pawn{Piece{0, 0, true}}
-		g.pieces[2] = &Rook{Piece{0, 0, false}}
-		g.pieces[3] = &Knight{Piece{0, 0, true}}
-		g.pieces[4] = &Bishop{Piece{0, 0, false}}
-		g.pieces[5] = &Queen{Piece{0, 0, true}}
-		g.pieces[6] = &King{Piece{0, 0, false}}
-		g.pieces[7] = &Bishop{Piece{0, 0, true}}
-		g.pieces[8] = &Knight{Piece{0, 0, false}}
-		g.pieces[9] = &Rook{Piece{0, 0, true}}
+		menuPieces := []ChessPiece{
+			&Pawn{Piece{0, 0, false}},
+			&Pawn{Piece{0, 0, true}},
+			&Rook{Piece{0, 0, false}},
+			&Knight{Piece{0, 0, true}},
+			&Bishop{Piece{0, 0, false}},
+			&Queen{Piece{0, 0, true}},
+			&King{Piece{0, 0, false}},
+			&Bishop{Piece{0, 0, true}},
+			&Knight{Piece{0, 0, false}},
+			&Rook{Piece{0, 0, true}},
+		}
 
 		for y := 0; y < 24; y++ {
 			for x := 0; x < 24; x++ {
@@ -858,87 +840,52 @@ func (g *Game) DrawMainMenu(generate bool) {
 				opPiece.GeoM.Scale(1.8, 1.8)
 				opPiece.GeoM.Translate(float64(x*100), float64(y*100))
 				opPiece.ColorM.Translate(0, 0, 0, -.7)
-				g.menuBgImage.DrawImage(g.pieces[(x+y)%10].Image(), opPiece)
+				g.menuBgImage.DrawImage(menuPieces[(x+y)%len(menuPieces)].Image(), opPiece)
 			}
 		}
 
 	}
 
-	g.mainMenuButtons[0].x = g.screenSize[0]/2 - 90
-	g.mainMenuButtons[0].y = g.screenSize[1]/2 + 8
-
-	g.mainMenuButtons[1].x = g.screenSize[0]/2 - 90
-	g.mainMenuButtons[1].y = g.screenSize[1]/2 + 118
+	g.menuUI.Draw(g.uiImage)
+}
 
-	opButton1 := &ebiten.DrawImageOptions{}
-	opButton1.GeoM.Translate(float64(g.mainMenuButtons[0].x), float64(g.mainMenuButtons[0].y))
-	if g.btnHoverIndex == 1 {
-		g.uiImage.DrawImage(g.btnPrimaryHover, opButton1)
-		text.Draw(g.uiImage, g.mainMenuButtons[0].text, g.uiFontSmall, g.mainMenuButtons[0].TextX(), g.mainMenuButtons[0].TextY(), colornames.Whitesmoke)
-	} else {
-		g.uiImage.DrawImage(g.btnPrimary, opButton1)
-		text.Draw(g.uiImage, g.mainMenuButtons[0].text, g.uiFontSmall, g.mainMenuButtons[0].TextX(), g.mainMenuButtons[0].TextY(), colornames.Gray)
+// InitPiecesAndImages starts a new game from the starting position
+func (g *Game) InitPiecesAndImages() {
+	if err := g.LoadFEN(StartingFEN); err != nil {
+		log.Fatal(err)
 	}
-
-	opButton2 := &ebiten.DrawImageOptions{}
-	opButton2.GeoM.Translate(float64(g.mainMenuButtons[1].x), float64(g.mainMenuButtons[1].y))
-	opButton2.ColorM.Translate(-.1, -.1, -.1, -.5) //TODO: remove color fading when feature added
-	g.uiImage.DrawImage(g.btnPrimary, opButton2)
-	text.Draw(g.uiImage, g.mainMenuButtons[1].text, g.uiFontSmall, g.mainMenuButtons[1].TextX(), g.mainMenuButtons[1].TextY(), colornames.Gray)
-
+	g.startFEN = ""
+	g.StartGame()
 }
 
-func (g *Game) InitPiecesAndImages() {
+// StartGame resets everything except the position (the pieces, side to move, castling rights and en passant
+// square) for a new game, so a game can also start from a position set up in the board editor
+func (g *Game) StartGame() {
 
 	g.moveNum = 0
 	g.selectedPiece = -1
+	g.gameUI.Focus(nil)
+	g.keyboardHeld = false
+	g.selectedRow = 6 //keyboard cursor starts on white's king pawn
+	g.selectedCol = 4
+	g.moveInputBox.SetText("")
+	g.moveInputMsg = ""
+	g.annotations = g.annotations[:0]
+	g.annotationStart = [2]int{-1, -1}
+	g.animations = g.animations[:0]
+	g.lastMove = [2][2]int{{-1, -1}, {-1, -1}}
 	g.selectedLocation[0] = 0.0
 	g.selectedLocation[1] = 0.0
 
-	g.pieces[0] = &Rook{Piece{0, 0, false}}
-	g.pieces[1] = &Knight{Piece{0, 1, false}}
-	g.pieces[2] = &Bishop{Piece{0, 2, false}}
-	g.pieces[3] = &Queen{Piece{0, 3, false}}
-	g.pieces[4] = &King{Piece{0, 4, false}}
-	g.pieces[5] = &Bishop{Piece{0, 5, false}}
-	g.pieces[6] = &Knight{Piece{0, 6, false}}
-	g.pieces[7] = &Rook{Piece{0, 7, false}}
-	g.pieces[8] = &Pawn{Piece{1, 0, false}}
-	g.pieces[9] = &Pawn{Piece{1, 1, false}}
-	g.pieces[10] = &Pawn{Piece{1, 2, false}}
-	g.pieces[11] = &Pawn{Piece{1, 3, false}}
-	g.pieces[12] = &Pawn{Piece{1, 4, false}}
-	g.pieces[13] = &Pawn{Piece{1, 5, false}}
-	g.pieces[14] = &Pawn{Piece{1, 6, false}}
-	g.pieces[15] = &Pawn{Piece{1, 7, false}}
-	g.pieces[16] = &Pawn{Piece{6, 0, true}}
-	g.pieces[17] = &Pawn{Piece{6, 1, true}}
-	g.pieces[18] = &Pawn{Piece{6, 2, true}}
-	g.pieces[19] = &Pawn{Piece{6, 3, true}}
-	g.pieces[20] = &Pawn{Piece{6, 4, true}}
-	g.pieces[21] = &Pawn{Piece{6, 5, true}}
-	g.pieces[22] = &Pawn{Piece{6, 6, true}}
-	g.pieces[23] = &Pawn{Piece{6, 7, true}}
-	g.pieces[24] = &Rook{Piece{7, 0, true}}
-	g.pieces[25] = &Knight{Piece{7, 1, true}}
-	g.pieces[26] = &Bishop{Piece{7, 2, true}}
-	g.pieces[27] = &Queen{Piece{7, 3, true}}
-	g.pieces[28] = &King{Piece{7, 4, true}}
-	g.pieces[29] = &Bishop{Piece{7, 5, true}}
-	g.pieces[30] = &Knight{Piece{7, 6, true}}
-	g.pieces[31] = &Rook{Piece{7, 7, true}}
-
-	//signifies if castle is available for either rook, both go false if king moves
-	g.whiteCastles[0] = true
-	g.whiteCastles[1] = true
-	g.blackCastles[0] = true
-	g.blackCastles[1] = true
-
 	g.checkmateNotChecked = true
 	g.gameOver = false
 	g.gameOverMsg = ""
-	g.inCheck = false
-	g.whitesTurn = true
+	g.result = GameResult{Score: "*"}
+	g.moves = g.moves[:0]
+	g.opening = Opening{}
+	g.UpdateOpening()
+	g.startTime = time.Now()
+	g.inCheck = g.KingInCheck(g.whitesTurn)
 
 	//included for re-initialization of a new game
 	g.gameImage.Clear()
@@ -950,22 +897,25 @@ func (g *Game) InitPiecesAndImages() {
 	g.DrawBoard()
 	g.DrawStaticPieces()
 	g.scheduleDraw = false
+
+	g.emit(EventGameStart)
 }
 
 func (g *Game) InitGame() {
 	g.gameType = -1
 
-	g.boardImage = ebiten.NewImage(Width, Height)
-	g.gameImage = ebiten.NewImage(Width, Height)
-	g.pieceImage = ebiten.NewImage(Width, Height)
+	g.boardImage = ebiten.NewImage(BoardSize, BoardSize)
+	g.gameImage = ebiten.NewImage(BoardSize, BoardSize)
+	g.pieceImage = ebiten.NewImage(BoardSize, BoardSize)
+	g.annotationImage = ebiten.NewImage(BoardSize, BoardSize)
 	g.menuBgImage = ebiten.NewImage(Width, Height)
 
-	//making these larger resolves scaling cut-off issues
-	g.movingImage = ebiten.NewImage(Width*2, Height*2)
-	g.uiImage = ebiten.NewImage(Width*2, Height*2)
+	//these follow the screen size, see ResizeScreenImages
+	g.movingImage = ebiten.NewImage(Width, Height)
+	g.uiImage = ebiten.NewImage(Width, Height)
 
-	g.screenSize[0] = Width
-	g.screenSize[1] = Height
+	g.sounds = NewSounds()
+	g.OnGameEvent(g.sounds.Play)
 
 	//Attempt to load font
 	tt, err := opentype.Parse(fonts.PressStart2P_ttf)
@@ -973,35 +923,11 @@ func (g *Game) InitGame() {
 		log.Fatal(err)
 	}
 
-	g.uiFontBig, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    38,
-		DPI:     FontDPI,
-		Hinting: font.HintingVertical,
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	g.uiFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    28,
-		DPI:     FontDPI,
-		Hinting: font.HintingVertical,
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	g.uiFontSmall, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    15,
-		DPI:     FontDPI,
-		Hinting: font.HintingVertical,
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
+	g.fontSource = tt
+	g.UpdateFonts(1)
 
 	fileLoc, _ := filepath.Abs("images/btnPrimary.png")
-	g.btnPrimary, _, err = ebitenutil.NewImageFromFile(fileLoc)
+	g.theme.Primary, _, err = ebitenutil.NewImageFromFile(fileLoc)
 	if err != nil {
 		return
 	}
@@ -1010,70 +936,147 @@ func (g *Game) InitGame() {
 	}
 
 	fileLoc, _ = filepath.Abs("images/btnPrimaryHover.png")
-	g.btnPrimaryHover, _, err = ebitenutil.NewImageFromFile(fileLoc)
+	g.theme.PrimaryHover, _, err = ebitenutil.NewImageFromFile(fileLoc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	fileLoc, _ = filepath.Abs("images/btnInfo.png")
-	g.btnInfo, _, err = ebitenutil.NewImageFromFile(fileLoc)
+	g.theme.Info, _, err = ebitenutil.NewImageFromFile(fileLoc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	fileLoc, _ = filepath.Abs("images/btnInfoHover.png")
-	g.btnInfoHover, _, err = ebitenutil.NewImageFromFile(fileLoc)
+	g.theme.InfoHover, _, err = ebitenutil.NewImageFromFile(fileLoc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	var localMatchBtn Button
-	localMatchBtn.fontSize = 15
-	localMatchBtn.text = "Local Match"
-	localMatchBtn.x = Width/2 - 90
-	localMatchBtn.y = Height/2 + 8
-
-	var versusBotBtn Button
-	versusBotBtn.fontSize = 15
-	versusBotBtn.text = "Versus Bot"
-	versusBotBtn.x = Width/2 - 90
-	versusBotBtn.y = Height/2 + 118
-
-	g.mainMenuButtons[0] = localMatchBtn
-	g.mainMenuButtons[1] = versusBotBtn
-
-	var mainMenuButton Button
-	mainMenuButton.x = 200
-	mainMenuButton.y = 370
-	mainMenuButton.text = "Main Menu"
-	mainMenuButton.fontSize = 15
-
-	var newGameButton Button
-	newGameButton.x = 200
-	newGameButton.y = 626
-	newGameButton.text = "New Game"
-	newGameButton.fontSize = 15
+	g.menuUI = NewUI(&g.theme, false)
+	g.mainMenuButtons[0] = NewButton("Local Match", func() {
+		g.colorsSwapped = false
+		g.boardFlipped = false
+		g.gameType = 1
+		g.InitPiecesAndImages()
+	})
+	g.mainMenuButtons[1] = NewButton("Board Editor", g.OpenEditor)
+	g.mainMenuButtons[2] = NewButton("Versus Bot", nil)
+	g.mainMenuButtons[2].SetEnabled(false) //TODO: enable when feature added
+	for _, btn := range g.mainMenuButtons {
+		btn.primary = true
+		g.menuUI.Add(btn)
+	}
+
+	//local matches turn the board to face whoever's move it is, unless the player turns this off
+	g.autoFlip = true
 
+	g.gameUI = NewUI(&g.theme, true)
+	mainMenuButton := NewButton("Main Menu", func() {
+		g.gameType = -1
+	})
+	mainMenuButton.primary = true
 	g.inGameButtons[0] = mainMenuButton
-	g.inGameButtons[1] = newGameButton
+	g.inGameButtons[1] = NewButton("New Game", g.InitPiecesAndImages)
+	g.inGameButtons[2] = NewButton("Flip Board", g.FlipBoard)
+	g.inGameButtons[3] = NewToggle("AutoFlip", g.autoFlip, func(on bool) {
+		g.ToggleAutoFlip()
+	})
+	g.resignButton = NewButton("Resign", g.ResignOrAbort)
+	g.inGameButtons[4] = g.resignButton
+	g.drawButton = NewButton("Offer Draw", g.OfferDraw)
+	g.inGameButtons[5] = g.drawButton
+	g.moveInputBox = NewTextInput("Type move", MoveInputMaxLen, g.SubmitMove)
+	g.gameUI.Add(g.inGameButtons[:]...)
+	g.gameUI.Add(g.moveInputBox)
+
+	g.editor = g.NewBoardEditor()
 
 	g.DrawMainMenu(true)
 }
 
+// Layout
+// Required by ebitengine. The screen is rendered at the device's real resolution so HiDPI displays stay sharp,
+// and ScreenLayout scales everything to fit it.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	deviceScale := ebiten.DeviceScaleFactor()
+	width := int(math.Ceil(float64(outsideWidth) * deviceScale))
+	height := int(math.Ceil(float64(outsideHeight) * deviceScale))
+
+	if width != g.layout.Width || height != g.layout.Height {
+		g.layout = ComputeLayout(width, height)
+		g.UpdateFonts(g.layout.Scale)
 
-	g.scaleX = float64(Width) / float64(outsideWidth)
-	g.scaleY = float64(Height) / float64(outsideHeight)
+		g.theme.Scale = g.layout.Scale
 
-	return outsideWidth, outsideHeight
+		for i, btn := range g.inGameButtons {
+			btn.SetBounds(g.layout.Buttons[i])
+		}
+		for i, btn := range g.mainMenuButtons {
+			btn.SetBounds(g.layout.MenuButtons[i])
+		}
+		g.moveInputBox.SetBounds(g.layout.MoveInput)
+		g.editor.SetBounds(&g.layout)
+
+		screen := Rect{W: float64(width), H: float64(height)}
+		g.menuUI.Resize(screen)
+		g.gameUI.Resize(screen)
+		g.editor.ui.Resize(screen)
+	}
+
+	return width, height
+}
+
+// UpdateFonts creates the UI font faces at scale times their size at the design resolution
+func (g *Game) UpdateFonts(scale float64) {
+	if scale == g.fontScale {
+		return
+	}
+	g.fontScale = scale
+
+	faces := []*font.Face{&g.uiFontBig, &g.uiFont, &g.uiFontSmall}
+	sizes := []float64{38, 28, 15}
+	for i, face := range faces {
+		newFace, err := opentype.NewFace(g.fontSource, &opentype.FaceOptions{
+			Size:    sizes[i] * scale,
+			DPI:     FontDPI,
+			Hinting: font.HintingVertical,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		*face = newFace
+	}
+	g.theme.Font = g.uiFontSmall
 }
 
 func main() {
+	animationSpeed := flag.Float64("animspeed", 1, "piece animation speed multiplier, 0 turns animations off")
+	tune := flag.String("tune", "", "tune the evaluation weights on a PGN or EPD `dataset` instead of playing")
+	evalParams := flag.String("evalparams", "", "evaluation weights `file` to start tuning from")
+	tuneOut := flag.String("tuneout", "evalparams.json", "`file` the tuned evaluation weights are saved to")
+	flag.Parse()
+
+	if *tune != "" {
+		params := DefaultEvalParams()
+		if *evalParams != "" {
+			var err error
+			if params, err = LoadEvalParams(*evalParams); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := RunTuning(*tune, params, *tuneOut); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	ebiten.SetWindowSize(1280, 720)
 	ebiten.SetWindowTitle("Chess by bojerg")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowSizeLimits(800, 450, 7680, 4320)
 	game := &Game{}
+	game.animationSpeed = *animationSpeed
 	game.InitGame()
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
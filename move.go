@@ -0,0 +1,81 @@
+package main
+
+// MoveFlag marks what kind of move a Move is. A move can have several, ex. a capture that promotes.
+type MoveFlag int
+
+const (
+	FlagCapture MoveFlag = 1 << iota
+	FlagEnPassant
+	FlagCastle
+	FlagDoublePush
+	FlagPromotion
+)
+
+// Move is a move of Piece from one square to another, as row, col pairs. Captured is the piece it takes, which
+// is not on the To square for en passant. Promotion is the SAN letter of the piece a pawn becomes on the last rank,
+// ex. 'Q', or 0. Moves returned by ChessPiece.Moves() are filled in completely. A move made on the board or typed
+// by the player only needs From, To and Promotion, and MakeMoveIfLegal looks up the rest.
+type Move struct {
+	From      [2]int
+	To        [2]int
+	Piece     ChessPiece
+	Captured  ChessPiece
+	Promotion byte
+	Flags     MoveFlag
+}
+
+// PromotionLetters are the pieces a pawn can promote to, in the order Moves() lists them
+const PromotionLetters = "QRBN"
+
+// NewMove is piece's move from its square to row, col, taking whatever stands there
+func (g *Game) NewMove(piece ChessPiece, row, col int) Move {
+	m := Move{From: [2]int{piece.Row(), piece.Col()}, To: [2]int{row, col}, Piece: piece}
+	if captured := g.PieceOn(row, col); captured != nil {
+		m.Captured = captured
+		m.Flags |= FlagCapture
+	}
+	return m
+}
+
+// MoveTo is the move of piece to row, col as the player makes it on the board. Pawns reaching the last rank become
+// queens, see ChoosePromotion for letting the player pick.
+func MoveTo(piece ChessPiece, row, col int) Move {
+	m := Move{From: [2]int{piece.Row(), piece.Col()}, To: [2]int{row, col}, Piece: piece}
+	if IsPawn(piece) && (row == 0 || row == 7) {
+		m.Promotion = 'Q'
+	}
+	return m
+}
+
+// Is reports whether the move has flag
+func (m Move) Is(flag MoveFlag) bool {
+	return m.Flags&flag != 0
+}
+
+// Matches reports whether m and other move between the same squares and promote to the same piece
+func (m Move) Matches(other Move) bool {
+	return m.From == other.From && m.To == other.To && m.Promotion == other.Promotion
+}
+
+// String writes the move in UCI long algebraic form, ex. "g1f3" or "e7e8q"
+func (m Move) String() string {
+	s := SquareName(m.From[0], m.From[1]) + SquareName(m.To[0], m.To[1])
+	if m.Promotion != 0 {
+		s += string(m.Promotion + 'a' - 'A')
+	}
+	return s
+}
+
+// appendPawnMove adds a pawn move to moves, or one move for each piece it can promote to when it reaches the last
+// rank
+func appendPawnMove(moves []Move, m Move) []Move {
+	if m.To[0] != 0 && m.To[0] != 7 {
+		return append(moves, m)
+	}
+	m.Flags |= FlagPromotion
+	for i := 0; i < len(PromotionLetters); i++ {
+		m.Promotion = PromotionLetters[i]
+		moves = append(moves, m)
+	}
+	return moves
+}
@@ -0,0 +1,131 @@
+package main
+
+import "testing"
+
+// TestMakeMoveIfLegalUndo makes moves that leave the king in check, and checks that MakeMoveIfLegal puts everything
+// back as it was: the pieces in the same slots of g.pieces, the board and the side to move
+func TestMakeMoveIfLegalUndo(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string // UCI
+	}{
+		// the pawn on g7 is pinned to the king along the seventh rank
+		{"promotion", "4k3/r5PK/8/8/8/8/8/8 w - - 0 1", "g7g8q"},
+		{"capture promotion", "4kn2/r5PK/8/8/8/8/8/8 w - - 0 1", "g7f8n"},
+		// taking en passant takes both pawns off the fifth rank, uncovering the rook
+		{"en passant", "8/8/8/KPp4r/8/8/8/4k3 w - c6 0 2", "b5c6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			before := append([]ChessPiece(nil), g.pieces...)
+			squares := make([][2]int, len(before))
+			for i, piece := range before {
+				squares[i] = [2]int{piece.Row(), piece.Col()}
+			}
+
+			// ParseMove doesn't check UCI moves for check, that is left to MakeMoveIfLegal
+			move, err := g.ParseMove(tt.move)
+			if err != nil {
+				t.Fatalf("ParseMove(%q): %v", tt.move, err)
+			}
+			if g.MakeMoveIfLegal(move) {
+				t.Fatalf("MakeMoveIfLegal(%s) = true, want false", tt.move)
+			}
+
+			if len(g.pieces) != len(before) || len(g.captured) != 0 {
+				t.Fatalf("%d pieces and %d captured after the illegal move, want %d and 0", len(g.pieces),
+					len(g.captured), len(before))
+			}
+			for i, piece := range before {
+				if g.pieces[i] != piece {
+					t.Errorf("g.pieces[%d] = %s, want %s", i, g.pieces[i].Name(), piece.Name())
+				}
+				if piece.Row() != squares[i][0] || piece.Col() != squares[i][1] {
+					t.Errorf("%s moved to %s, want it back on %s", piece.Name(), SquareName(piece.Row(), piece.Col()),
+						SquareName(squares[i][0], squares[i][1]))
+				}
+				if g.PieceOn(squares[i][0], squares[i][1]) != piece {
+					t.Errorf("the board doesn't have %s on %s", piece.Name(), SquareName(squares[i][0], squares[i][1]))
+				}
+			}
+			if got := g.FEN(); got != tt.fen {
+				t.Errorf("FEN() = %q after the illegal move, want %q", got, tt.fen)
+			}
+		})
+	}
+}
+
+func TestMakeMoveIfLegal(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		move     string // UCI
+		want     string // FEN after the move
+		captured string // FEN letter of the piece taken, if any
+	}{
+		{"en passant", "4k3/8/8/KPp5/8/8/8/8 w - c6 0 2", "b5c6", "4k3/8/2P5/K7/8/8/8/8 b - - 0 2", "p"},
+		{"promotion", "4k3/6P1/8/8/8/8/8/4K3 w - - 0 1", "g7g8r", "4k1R1/8/8/8/8/8/8/4K3 b - - 0 1", ""},
+		{"capture promotion", "4kn2/6P1/8/8/8/8/8/4K3 w - - 0 1", "g7f8b", "4kB2/8/8/8/8/8/8/4K3 b - - 0 1", "n"},
+		{"castle", "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1c1", "4k3/8/8/8/8/8/8/2KR3R b - - 1 1", ""},
+		{"double push", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", "e2e4", "4k3/8/8/8/4P3/8/8/4K3 b - e3 0 1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			move, err := g.ParseMove(tt.move)
+			if err != nil {
+				t.Fatalf("ParseMove(%q): %v", tt.move, err)
+			}
+			if !g.MakeMoveIfLegal(move) {
+				t.Fatalf("MakeMoveIfLegal(%s) = false, want true", tt.move)
+			}
+			if got := g.FEN(); got != tt.want {
+				t.Errorf("FEN() = %q, want %q", got, tt.want)
+			}
+			captured := ""
+			for _, piece := range g.captured {
+				captured += string(PieceLetter(piece))
+			}
+			if captured != tt.captured {
+				t.Errorf("captured %q, want %q", captured, tt.captured)
+			}
+		})
+	}
+}
+
+func TestMakeMoveIfLegalEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string // UCI
+		want GameEvent
+	}{
+		{"move", StartingFEN, "g1f3", EventMove},
+		{"capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", EventCapture},
+		{"en passant", "4k3/8/8/KPp5/8/8/8/8 w - c6 0 2", "b5c6", EventCapture},
+		{"castle", "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1", "e1g1", EventCastle},
+		{"promotion", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8n", EventPromotion},
+		{"capture promotion", "rn2k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8r", EventPromotion},
+		{"promotion with check", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", EventCheck},
+		{"illegal", "4k3/r5PK/8/8/8/8/8/8 w - - 0 1", "g7g8q", EventIllegalMove},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			var events []GameEvent
+			g.OnGameEvent(func(event GameEvent) {
+				events = append(events, event)
+			})
+			move, err := g.ParseMove(tt.move)
+			if err != nil {
+				t.Fatalf("ParseMove(%q): %v", tt.move, err)
+			}
+			g.MakeMoveIfLegal(move)
+			if len(events) != 1 || events[0] != tt.want {
+				t.Errorf("MakeMoveIfLegal(%s) emitted %v, want [%v]", tt.move, events, tt.want)
+			}
+		})
+	}
+}
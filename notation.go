@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// sanPieceNames maps the piece letters used by SAN to the suffix of ChessPiece.Name()
+var sanPieceNames = map[byte]string{
+	'K': "king",
+	'Q': "queen",
+	'R': "rook",
+	'B': "bishop",
+	'N': "knight",
+}
+
+// sanPieceLetters is the inverse of sanPieceNames
+var sanPieceLetters = map[string]string{
+	"king":   "K",
+	"queen":  "Q",
+	"rook":   "R",
+	"bishop": "B",
+	"knight": "N",
+}
+
+// ParseSquare converts a square in algebraic notation (ex. "e4") to a board row and col.
+// Rank 8 is row 0 and file a is col 0, matching the order of the ranks in a FEN.
+func ParseSquare(s string) (row int, col int, err error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return -1, -1, fmt.Errorf("%q is not a square", s)
+	}
+	return int('8' - s[1]), int(s[0] - 'a'), nil
+}
+
+// SquareName is the inverse of ParseSquare, ex. row 7 col 6 >> "g1"
+func SquareName(row, col int) string {
+	return string([]byte{byte('a' + col), byte('8' - row)})
+}
+
+// isUCIMove reports whether s looks like a move in UCI long algebraic form, ex. "g1f3" or "e7e8q"
+func isUCIMove(s string) bool {
+	if len(s) != 4 && len(s) != 5 {
+		return false
+	}
+	_, _, fromErr := ParseSquare(s[0:2])
+	_, _, toErr := ParseSquare(s[2:4])
+	return fromErr == nil && toErr == nil
+}
+
+// ParseMove converts a move typed by the player into a Move. Both SAN (ex. "Nf3", "exd5", "O-O", "e8=Q") and UCI
+// (ex. "g1f3", "e7e8q") are accepted. Only the pieces of the side to move are considered, and the move must appear in
+// that piece's Moves() list. A pawn reaching the last rank needs the piece it promotes to. A SAN move must also be
+// legal, since that decides which piece is meant, while MakeMoveIfLegal has the final say on UCI moves and castling.
+func (g *Game) ParseMove(s string) (Move, error) {
+	// check, mate and annotation symbols don't change which move is meant
+	s = strings.TrimRight(strings.TrimSpace(s), "+#!?")
+	if s == "" {
+		return Move{}, errors.New("no move entered")
+	}
+
+	if isUCIMove(s) {
+		fromRow, fromCol, _ := ParseSquare(s[0:2])
+		row, col, _ := ParseSquare(s[2:4])
+		piece := g.PieceOn(fromRow, fromCol)
+		if piece == nil || piece.White() != g.whitesTurn {
+			return Move{}, fmt.Errorf("none of your pieces are on %s", s[0:2])
+		}
+		move := Move{From: [2]int{fromRow, fromCol}, To: [2]int{row, col}, Piece: piece}
+		if len(s) == 5 {
+			letter := byte(unicode.ToUpper(rune(s[4])))
+			if strings.IndexByte(PromotionLetters, letter) == -1 {
+				return Move{}, fmt.Errorf("bad promotion piece %q, use one of q, r, b or n", s[4:])
+			}
+			move.Promotion = letter
+		}
+		return g.findMove(move)
+	}
+
+	// castling is written as a king move of two files
+	castle := strings.ReplaceAll(s, "0", "O")
+	if castle == "O-O" || castle == "O-O-O" {
+		for _, piece := range g.pieces {
+			if IsKing(piece) && piece.White() == g.whitesTurn {
+				col := piece.Col() + 2
+				if castle == "O-O-O" {
+					col = piece.Col() - 2
+				}
+				if !g.hasMove(MoveTo(piece, piece.Row(), col)) {
+					return Move{}, errors.New("castling is not available")
+				}
+				return MoveTo(piece, piece.Row(), col), nil
+			}
+		}
+	}
+
+	// SAN: [piece letter][disambiguation file/rank][x]<destination>[=promotion]
+	var promotion byte
+	if last := s[len(s)-1]; strings.IndexByte(PromotionLetters, last) != -1 && len(s) > 2 {
+		promotion = last
+		s = strings.TrimSuffix(s[:len(s)-1], "=")
+	}
+	pieceName := "pawn"
+	if name, ok := sanPieceNames[s[0]]; ok {
+		pieceName = name
+		s = s[1:]
+	}
+	s = strings.ReplaceAll(s, "x", "")
+	if len(s) < 2 {
+		return Move{}, errors.New("move is missing a destination square")
+	}
+	row, col, err := ParseSquare(s[len(s)-2:])
+	if err != nil {
+		return Move{}, err
+	}
+	if promotion != 0 && pieceName != "pawn" {
+		return Move{}, errors.New("only pawns can promote")
+	}
+
+	disambiguation := s[:len(s)-2]
+	fromCol, fromRow := -1, -1
+	for i := 0; i < len(disambiguation); i++ {
+		switch c := disambiguation[i]; {
+		case c >= 'a' && c <= 'h':
+			fromCol = int(c - 'a')
+		case c >= '1' && c <= '8':
+			fromRow = int('8' - c)
+		default:
+			return Move{}, fmt.Errorf("can't read move %q", s)
+		}
+	}
+
+	var candidates []Move
+	for _, piece := range g.pieces {
+		if piece.White() != g.whitesTurn || piece.Name()[6:] != pieceName {
+			continue
+		}
+		if (fromCol != -1 && piece.Col() != fromCol) || (fromRow != -1 && piece.Row() != fromRow) {
+			continue
+		}
+		move := Move{From: [2]int{piece.Row(), piece.Col()}, To: [2]int{row, col}, Piece: piece, Promotion: promotion}
+		if g.hasMove(move) {
+			candidates = append(candidates, move)
+		}
+	}
+	if len(candidates) == 0 {
+		if pieceName == "pawn" && promotion == 0 && (row == 0 || row == 7) {
+			return Move{}, fmt.Errorf("say which piece to promote to, ex. %s=Q", SquareName(row, col))
+		}
+		return Move{}, fmt.Errorf("no %s can move to %s", pieceName, SquareName(row, col))
+	}
+
+	// SAN leaves out the disambiguation when the other piece is pinned, so only count pieces that can legally move
+	var legalCandidates []Move
+	for _, move := range candidates {
+		if g.IsLegalMove(move) {
+			legalCandidates = append(legalCandidates, move)
+		}
+	}
+	switch len(legalCandidates) {
+	case 0:
+		return Move{}, errors.New("that move would leave your king in check")
+	case 1:
+		return legalCandidates[0], nil
+	}
+	return Move{}, errors.New("ambiguous move, add the file or rank of the piece")
+}
+
+// findMove checks that move is among the moves returned by move.Piece.Moves(), explaining why not when it isn't
+func (g *Game) findMove(move Move) (Move, error) {
+	if g.hasMove(move) {
+		return move, nil
+	}
+	to := SquareName(move.To[0], move.To[1])
+	if IsPawn(move.Piece) && (move.To[0] == 0 || move.To[0] == 7) && move.Promotion == 0 {
+		return Move{}, fmt.Errorf("say which piece to promote to, ex. %s%sq", SquareName(move.From[0], move.From[1]), to)
+	}
+	return Move{}, fmt.Errorf("%s can't move to %s", move.Piece.Name(), to)
+}
+
+// hasMove reports whether move is among the moves returned by move.Piece.Moves()
+func (g *Game) hasMove(move Move) bool {
+	for _, m := range move.Piece.Moves(*g) {
+		if m.Matches(move) {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeMoveFromNotation parses a SAN or UCI move and plays it through PlayMove
+func (g *Game) MakeMoveFromNotation(s string) error {
+	if g.gameOver {
+		return errors.New("the game is over")
+	}
+	move, err := g.ParseMove(s)
+	if err != nil {
+		return err
+	}
+
+	legal := g.PlayMove(move, false)
+	g.selectedPiece = -1
+	g.scheduleDraw = true
+
+	if !legal {
+		return errors.New("that move would leave your king in check")
+	}
+	return nil
+}
+
+// SAN writes m in SAN, ex. "Nbd7", "exd5" or "e8=Q". It must be called before the move is made. Check and mate
+// symbols depend on the position after the move, so they are added by the caller.
+func (g *Game) SAN(m Move) string {
+	piece := g.PieceOn(m.From[0], m.From[1])
+	if piece == nil {
+		return m.String()
+	}
+	row, col := m.To[0], m.To[1]
+	if IsKing(piece) && col-piece.Col() == 2 {
+		return "O-O"
+	}
+	if IsKing(piece) && col-piece.Col() == -2 {
+		return "O-O-O"
+	}
+
+	target := g.PieceOn(row, col)
+	capture := target != nil && target.White() != piece.White()
+	if IsPawn(piece) {
+		san := SquareName(row, col)
+		// a pawn changing files always captures, even en passant onto an empty square
+		if col != piece.Col() {
+			san = SquareName(row, piece.Col())[:1] + "x" + san
+		}
+		if m.Promotion != 0 {
+			san += "=" + string(m.Promotion)
+		}
+		return san
+	}
+
+	// name the file, rank or both of the piece when another piece of the same kind could also move there
+	sameFile, sameRank, ambiguous := false, false, false
+	for _, other := range g.pieces {
+		if other == piece || other.White() != piece.White() || other.Name() != piece.Name() {
+			continue
+		}
+		move := Move{From: [2]int{other.Row(), other.Col()}, To: m.To, Piece: other}
+		if g.hasMove(move) && g.IsLegalMove(move) {
+			ambiguous = true
+			sameFile = sameFile || other.Col() == piece.Col()
+			sameRank = sameRank || other.Row() == piece.Row()
+		}
+	}
+
+	from := SquareName(piece.Row(), piece.Col())
+	san := sanPieceLetters[piece.Name()[6:]]
+	switch {
+	case !ambiguous:
+	case !sameFile:
+		san += from[:1]
+	case !sameRank:
+		san += from[1:]
+	default:
+		san += from
+	}
+	if capture {
+		san += "x"
+	}
+	return san + SquareName(row, col)
+}
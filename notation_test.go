@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// perft counts the leaf nodes of the legal move tree to depth plies, which catches move generation bugs when
+// compared with the known counts for a position
+func perft(g *Game, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	nodes := 0
+	for i, piece := range g.pieces {
+		if piece.White() != g.whitesTurn {
+			continue
+		}
+		for _, move := range g.LegalMoves(i) {
+			nodes += perft(playOnCopy(g, move), depth-1)
+		}
+	}
+	return nodes
+}
+
+// playOnCopy makes move on a copy of g, the same way IsLegalMove tries moves, and returns the copy
+func playOnCopy(g *Game, move Move) *Game {
+	scratch := *g
+	scratch.eventHandlers = nil
+	pieces := make([]ChessPiece, len(g.pieces))
+	for i, piece := range g.pieces {
+		pieces[i] = ClonePiece(piece)
+	}
+	scratch.SetPieces(pieces)
+	scratch.captured = append([]ChessPiece(nil), g.captured...)
+	scratch.MakeMoveIfLegal(move)
+	return &scratch
+}
+
+// legalMoves lists every legal move of the side to move
+func legalMoves(g *Game) []Move {
+	var moves []Move
+	for i, piece := range g.pieces {
+		if piece.White() == g.whitesTurn {
+			moves = append(moves, g.LegalMoves(i)...)
+		}
+	}
+	return moves
+}
+
+func loadTestGame(t *testing.T, fen string) *Game {
+	t.Helper()
+	g := &Game{}
+	if err := g.LoadFEN(fen); err != nil {
+		t.Fatalf("LoadFEN(%q): %v", fen, err)
+	}
+	g.selectedPiece = -1
+	return g
+}
+
+// Positions and counts from https://www.chessprogramming.org/Perft_Results
+var perftPositions = []struct {
+	name  string
+	fen   string
+	depth int
+	nodes int
+}{
+	{"start", StartingFEN, 3, 8902},
+	{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2, 2039},
+	{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 3, 2812},
+	{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 2, 264},
+	{"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", 2, 1486},
+}
+
+func TestPerft(t *testing.T) {
+	for _, tt := range perftPositions {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			if got := perft(g, tt.depth); got != tt.nodes {
+				t.Errorf("perft(%d) = %d, want %d", tt.depth, got, tt.nodes)
+			}
+		})
+	}
+}
+
+// TestSANRoundTrip writes every legal move in SAN and UCI and checks that ParseMove reads back the same move
+func TestSANRoundTrip(t *testing.T) {
+	for _, tt := range perftPositions {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			for _, move := range legalMoves(g) {
+				for _, s := range []string{g.SAN(move), move.String()} {
+					parsed, err := g.ParseMove(s)
+					if err != nil {
+						t.Errorf("ParseMove(%q) for %v: %v", s, move, err)
+					} else if !parsed.Matches(move) {
+						t.Errorf("ParseMove(%q) = %v, want %v", s, parsed, move)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSANDisambiguation(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string // UCI
+		want string
+	}{
+		{"by file", "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "b1d2", "Nbd2"},
+		{"by rank", "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "a1a3", "R1a3"},
+		{"by file and rank", "4k3/8/8/8/4Q2Q/8/8/K6Q w - - 0 1", "h4e1", "Qh4e1"},
+		{"other piece pinned", "4k3/3r4/8/8/8/8/3N4/1N1K4 w - - 0 1", "b1c3", "Nc3"},
+		{"other piece can't reach", "4k3/8/8/8/8/8/8/N3K1N1 w - - 0 1", "g1f3", "Nf3"},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", "exd5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", "exd6"},
+		{"promotion", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8n", "b8=N"},
+		{"capture promotion", "rn2k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8r", "bxa8=R"},
+		{"castle", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", "O-O-O"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			move, err := g.ParseMove(tt.move)
+			if err != nil {
+				t.Fatalf("ParseMove(%q): %v", tt.move, err)
+			}
+			if got := g.SAN(move); got != tt.want {
+				t.Errorf("SAN(%s) = %q, want %q", tt.move, got, tt.want)
+			}
+			parsed, err := g.ParseMove(tt.want)
+			if err != nil || !parsed.Matches(move) {
+				t.Errorf("ParseMove(%q) = %v, %v, want %s", tt.want, parsed, err, tt.move)
+			}
+		})
+	}
+}
+
+func TestParseMoveErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string
+		want string // part of the error message
+	}{
+		{"empty", StartingFEN, " ", "no move"},
+		{"no piece there", StartingFEN, "e3e4", "none of your pieces"},
+		{"opponent's piece", StartingFEN, "e7e5", "none of your pieces"},
+		{"bad promotion piece", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8k", "bad promotion piece"},
+		{"promotion without piece", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8", "which piece to promote"},
+		{"uci promotion without piece", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8", "which piece to promote"},
+		{"only pawns promote", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "Ra8=Q", "only pawns"},
+		{"ambiguous", "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nd2", "ambiguous"},
+		{"pinned", "4k3/8/8/8/4r3/8/4N3/4K3 w - - 0 1", "Nd4", "leave your king in check"},
+		{"can't reach", StartingFEN, "Nd4", "no knight"},
+		{"castling unavailable", StartingFEN, "O-O", "castling"},
+		{"not a square", StartingFEN, "Nz9", "not a square"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, tt.fen)
+			move, err := g.ParseMove(tt.move)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseMove(%q) = %v, %v, want an error about %q", tt.move, move, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMoveUppercasePromotion(t *testing.T) {
+	g := loadTestGame(t, "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1")
+	move, err := g.ParseMove("b7b8Q")
+	if err != nil || move.Promotion != 'Q' {
+		t.Errorf("ParseMove(%q) = %v, %v, want a promotion to a queen", "b7b8Q", move, err)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Opening is a named opening with its code from the Encyclopaedia of Chess Openings (ECO), ex. C50 Italian Game
+type Opening struct {
+	ECO  string
+	Name string
+}
+
+func (o Opening) String() string {
+	return o.ECO + " " + o.Name
+}
+
+// ecoTable lists the openings the game can name, each with a line of SAN moves from the starting position that
+// reaches it. Openings are matched by the position the line ends in rather than by the moves, so a game that gets
+// there in a different order (ex. 1.Nf3 d5 2.d4 for 1.d4 d5 2.Nf3) is still recognized. The table covers the common
+// openings and their main variations, not every ECO code.
+var ecoTable = []struct {
+	eco   string
+	name  string
+	moves string
+}{
+	// flank and irregular openings
+	{"A00", "Polish Opening", "b4"},
+	{"A00", "Grob Opening", "g4"},
+	{"A00", "Hungarian Opening", "g3"},
+	{"A00", "Van Geet Opening", "Nc3"},
+	{"A00", "Mieses Opening", "d3"},
+	{"A00", "Van't Kruijs Opening", "e3"},
+	{"A00", "Amar Opening", "Nh3"},
+	{"A01", "Nimzo-Larsen Attack", "b3"},
+	{"A02", "Bird Opening", "f4"},
+	{"A02", "Bird Opening: From's Gambit", "f4 e5"},
+	{"A03", "Bird Opening: Dutch Variation", "f4 d5"},
+	{"A04", "Zukertort Opening", "Nf3"},
+	{"A05", "Zukertort Opening: Quiet System", "Nf3 Nf6"},
+	{"A06", "Zukertort Opening", "Nf3 d5"},
+	{"A07", "King's Indian Attack", "Nf3 d5 g3"},
+	{"A09", "Réti Opening", "Nf3 d5 c4"},
+	{"A10", "English Opening", "c4"},
+	{"A13", "English Opening: Agincourt Defense", "c4 e6"},
+	{"A15", "English Opening: Anglo-Indian Defense", "c4 Nf6"},
+	{"A20", "English Opening: King's English Variation", "c4 e5"},
+	{"A25", "English Opening: King's English Variation, Reversed Closed Sicilian", "c4 e5 Nc3 Nc6"},
+	{"A30", "English Opening: Symmetrical Variation", "c4 c5"},
+
+	// 1.d4 without 1...d5
+	{"A40", "Queen's Pawn Game", "d4"},
+	{"A40", "Englund Gambit", "d4 e5"},
+	{"A40", "Modern Defense", "d4 g6"},
+	{"A43", "Benoni Defense: Old Benoni", "d4 c5"},
+	{"A45", "Indian Defense", "d4 Nf6"},
+	{"A45", "Trompowsky Attack", "d4 Nf6 Bg5"},
+	{"A46", "Indian Defense: Knights Variation", "d4 Nf6 Nf3"},
+	{"A50", "Indian Defense: Normal Variation", "d4 Nf6 c4"},
+	{"A51", "Budapest Defense", "d4 Nf6 c4 e5"},
+	{"A53", "Old Indian Defense", "d4 Nf6 c4 d6"},
+	{"A56", "Benoni Defense", "d4 Nf6 c4 c5"},
+	{"A57", "Benko Gambit", "d4 Nf6 c4 c5 d5 b5"},
+	{"A60", "Benoni Defense: Modern Variation", "d4 Nf6 c4 c5 d5 e6"},
+	{"A80", "Dutch Defense", "d4 f5"},
+	{"A82", "Dutch Defense: Staunton Gambit", "d4 f5 e4"},
+
+	// 1.e4 without 1...e5
+	{"B00", "King's Pawn Game", "e4"},
+	{"B00", "Nimzowitsch Defense", "e4 Nc6"},
+	{"B00", "Owen Defense", "e4 b6"},
+	{"B01", "Scandinavian Defense", "e4 d5"},
+	{"B01", "Scandinavian Defense: Modern Variation", "e4 d5 exd5 Nf6"},
+	{"B01", "Scandinavian Defense: Main Line", "e4 d5 exd5 Qxd5 Nc3 Qa5"},
+	{"B02", "Alekhine Defense", "e4 Nf6"},
+	{"B03", "Alekhine Defense: Four Pawns Attack", "e4 Nf6 e5 Nd5 d4 d6 c4 Nb6 f4"},
+	{"B06", "Modern Defense", "e4 g6"},
+	{"B07", "Pirc Defense", "e4 d6 d4 Nf6 Nc3 g6"},
+	{"B10", "Caro-Kann Defense", "e4 c6"},
+	{"B11", "Caro-Kann Defense: Two Knights Attack", "e4 c6 Nc3 d5 Nf3"},
+	{"B12", "Caro-Kann Defense: Advance Variation", "e4 c6 d4 d5 e5"},
+	{"B13", "Caro-Kann Defense: Exchange Variation", "e4 c6 d4 d5 exd5 cxd5"},
+	{"B13", "Caro-Kann Defense: Panov Attack", "e4 c6 d4 d5 exd5 cxd5 c4"},
+	{"B17", "Caro-Kann Defense: Karpov Variation", "e4 c6 d4 d5 Nc3 dxe4 Nxe4 Nd7"},
+	{"B18", "Caro-Kann Defense: Classical Variation", "e4 c6 d4 d5 Nc3 dxe4 Nxe4 Bf5"},
+	{"B20", "Sicilian Defense", "e4 c5"},
+	{"B21", "Sicilian Defense: Smith-Morra Gambit", "e4 c5 d4 cxd4 c3"},
+	{"B22", "Sicilian Defense: Alapin Variation", "e4 c5 c3"},
+	{"B23", "Sicilian Defense: Closed", "e4 c5 Nc3"},
+	{"B27", "Sicilian Defense", "e4 c5 Nf3"},
+	{"B30", "Sicilian Defense: Old Sicilian", "e4 c5 Nf3 Nc6"},
+	{"B30", "Sicilian Defense: Rossolimo Variation", "e4 c5 Nf3 Nc6 Bb5"},
+	{"B32", "Sicilian Defense: Open", "e4 c5 Nf3 Nc6 d4 cxd4 Nxd4"},
+	{"B33", "Sicilian Defense: Sveshnikov Variation", "e4 c5 Nf3 Nc6 d4 cxd4 Nxd4 Nf6 Nc3 e5"},
+	{"B34", "Sicilian Defense: Accelerated Dragon", "e4 c5 Nf3 Nc6 d4 cxd4 Nxd4 g6"},
+	{"B40", "Sicilian Defense: French Variation", "e4 c5 Nf3 e6"},
+	{"B41", "Sicilian Defense: Kan Variation", "e4 c5 Nf3 e6 d4 cxd4 Nxd4 a6"},
+	{"B44", "Sicilian Defense: Taimanov Variation", "e4 c5 Nf3 e6 d4 cxd4 Nxd4 Nc6"},
+	{"B50", "Sicilian Defense", "e4 c5 Nf3 d6"},
+	{"B51", "Sicilian Defense: Moscow Variation", "e4 c5 Nf3 d6 Bb5+"},
+	{"B56", "Sicilian Defense: Classical Variation", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 Nc6"},
+	{"B60", "Sicilian Defense: Richter-Rauzer Variation", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 Nc6 Bg5"},
+	{"B70", "Sicilian Defense: Dragon Variation", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 g6"},
+	{"B76", "Sicilian Defense: Dragon Variation, Yugoslav Attack", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 g6 Be3 Bg7 f3 O-O Qd2"},
+	{"B80", "Sicilian Defense: Scheveningen Variation", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 e6"},
+	{"B90", "Sicilian Defense: Najdorf Variation", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 a6"},
+
+	// 1.e4 e5 and the French
+	{"C00", "French Defense", "e4 e6"},
+	{"C01", "French Defense: Exchange Variation", "e4 e6 d4 d5 exd5"},
+	{"C02", "French Defense: Advance Variation", "e4 e6 d4 d5 e5"},
+	{"C03", "French Defense: Tarrasch Variation", "e4 e6 d4 d5 Nd2"},
+	{"C10", "French Defense: Paulsen Variation", "e4 e6 d4 d5 Nc3"},
+	{"C10", "French Defense: Rubinstein Variation", "e4 e6 d4 d5 Nc3 dxe4"},
+	{"C11", "French Defense: Classical Variation", "e4 e6 d4 d5 Nc3 Nf6"},
+	{"C15", "French Defense: Winawer Variation", "e4 e6 d4 d5 Nc3 Bb4"},
+	{"C20", "King's Pawn Game", "e4 e5"},
+	{"C21", "Center Game", "e4 e5 d4 exd4"},
+	{"C21", "Danish Gambit", "e4 e5 d4 exd4 c3"},
+	{"C23", "Bishop's Opening", "e4 e5 Bc4"},
+	{"C25", "Vienna Game", "e4 e5 Nc3"},
+	{"C30", "King's Gambit", "e4 e5 f4"},
+	{"C31", "King's Gambit Declined: Falkbeer Countergambit", "e4 e5 f4 d5"},
+	{"C33", "King's Gambit Accepted", "e4 e5 f4 exf4"},
+	{"C40", "King's Knight Opening", "e4 e5 Nf3"},
+	{"C40", "Latvian Gambit", "e4 e5 Nf3 f5"},
+	{"C40", "Elephant Gambit", "e4 e5 Nf3 d5"},
+	{"C41", "Philidor Defense", "e4 e5 Nf3 d6"},
+	{"C42", "Petrov's Defense", "e4 e5 Nf3 Nf6"},
+	{"C42", "Petrov's Defense: Stafford Gambit", "e4 e5 Nf3 Nf6 Nxe5 Nc6"},
+	{"C44", "King's Knight Opening: Normal Variation", "e4 e5 Nf3 Nc6"},
+	{"C44", "Ponziani Opening", "e4 e5 Nf3 Nc6 c3"},
+	{"C44", "Scotch Game", "e4 e5 Nf3 Nc6 d4"},
+	{"C44", "Scotch Gambit", "e4 e5 Nf3 Nc6 d4 exd4 Bc4"},
+	{"C45", "Scotch Game", "e4 e5 Nf3 Nc6 d4 exd4 Nxd4"},
+	{"C46", "Three Knights Opening", "e4 e5 Nf3 Nc6 Nc3"},
+	{"C47", "Four Knights Game", "e4 e5 Nf3 Nc6 Nc3 Nf6"},
+	{"C47", "Four Knights Game: Scotch Variation", "e4 e5 Nf3 Nc6 Nc3 Nf6 d4"},
+	{"C48", "Four Knights Game: Spanish Variation", "e4 e5 Nf3 Nc6 Nc3 Nf6 Bb5"},
+	{"C50", "Italian Game", "e4 e5 Nf3 Nc6 Bc4"},
+	{"C50", "Italian Game: Hungarian Defense", "e4 e5 Nf3 Nc6 Bc4 Be7"},
+	{"C50", "Giuoco Piano", "e4 e5 Nf3 Nc6 Bc4 Bc5"},
+	{"C50", "Italian Game: Giuoco Pianissimo", "e4 e5 Nf3 Nc6 Bc4 Bc5 d3"},
+	{"C51", "Evans Gambit", "e4 e5 Nf3 Nc6 Bc4 Bc5 b4"},
+	{"C55", "Italian Game: Two Knights Defense", "e4 e5 Nf3 Nc6 Bc4 Nf6"},
+	{"C57", "Italian Game: Two Knights Defense, Knight Attack", "e4 e5 Nf3 Nc6 Bc4 Nf6 Ng5"},
+	{"C57", "Italian Game: Two Knights Defense, Traxler Counterattack", "e4 e5 Nf3 Nc6 Bc4 Nf6 Ng5 Bc5"},
+	{"C57", "Italian Game: Two Knights Defense, Fried Liver Attack", "e4 e5 Nf3 Nc6 Bc4 Nf6 Ng5 d5 exd5 Nxd5 Nxf7"},
+	{"C60", "Ruy Lopez", "e4 e5 Nf3 Nc6 Bb5"},
+	{"C62", "Ruy Lopez: Steinitz Defense", "e4 e5 Nf3 Nc6 Bb5 d6"},
+	{"C63", "Ruy Lopez: Schliemann Defense", "e4 e5 Nf3 Nc6 Bb5 f5"},
+	{"C64", "Ruy Lopez: Classical Variation", "e4 e5 Nf3 Nc6 Bb5 Bc5"},
+	{"C65", "Ruy Lopez: Berlin Defense", "e4 e5 Nf3 Nc6 Bb5 Nf6"},
+	{"C68", "Ruy Lopez: Exchange Variation", "e4 e5 Nf3 Nc6 Bb5 a6 Bxc6"},
+	{"C70", "Ruy Lopez: Morphy Defense", "e4 e5 Nf3 Nc6 Bb5 a6"},
+	{"C80", "Ruy Lopez: Open", "e4 e5 Nf3 Nc6 Bb5 a6 Ba4 Nf6 O-O Nxe4"},
+	{"C84", "Ruy Lopez: Closed", "e4 e5 Nf3 Nc6 Bb5 a6 Ba4 Nf6 O-O Be7"},
+	{"C89", "Ruy Lopez: Marshall Attack", "e4 e5 Nf3 Nc6 Bb5 a6 Ba4 Nf6 O-O Be7 Re1 b5 Bb3 O-O c3 d5"},
+
+	// 1.d4 d5
+	{"D00", "Queen's Pawn Game", "d4 d5"},
+	{"D00", "Blackmar-Diemer Gambit", "d4 d5 e4"},
+	{"D00", "Queen's Pawn Game: Accelerated London System", "d4 d5 Bf4"},
+	{"D01", "Richter-Veresov Attack", "d4 d5 Nc3 Nf6 Bg5"},
+	{"D02", "Queen's Pawn Game: Zukertort Variation", "d4 d5 Nf3"},
+	{"D02", "Queen's Pawn Game: London System", "d4 d5 Nf3 Nf6 Bf4"},
+	{"D04", "Queen's Pawn Game: Colle System", "d4 d5 Nf3 Nf6 e3"},
+	{"D06", "Queen's Gambit", "d4 d5 c4"},
+	{"D07", "Queen's Gambit Declined: Chigorin Defense", "d4 d5 c4 Nc6"},
+	{"D08", "Queen's Gambit Declined: Albin Countergambit", "d4 d5 c4 e5"},
+	{"D10", "Slav Defense", "d4 d5 c4 c6"},
+	{"D20", "Queen's Gambit Accepted", "d4 d5 c4 dxc4"},
+	{"D30", "Queen's Gambit Declined", "d4 d5 c4 e6"},
+	{"D31", "Queen's Gambit Declined: Queen's Knight Variation", "d4 d5 c4 e6 Nc3"},
+	{"D32", "Tarrasch Defense", "d4 d5 c4 e6 Nc3 c5"},
+	{"D35", "Queen's Gambit Declined: Exchange Variation", "d4 d5 c4 e6 Nc3 Nf6 cxd5"},
+	{"D43", "Semi-Slav Defense", "d4 d5 c4 e6 Nc3 Nf6 Nf3 c6"},
+	{"D80", "Grünfeld Defense", "d4 Nf6 c4 g6 Nc3 d5"},
+	{"D85", "Grünfeld Defense: Exchange Variation", "d4 Nf6 c4 g6 Nc3 d5 cxd5 Nxd5"},
+
+	// 1.d4 Nf6 2.c4 e6 and the King's Indian
+	{"E00", "Indian Defense", "d4 Nf6 c4 e6"},
+	{"E01", "Catalan Opening", "d4 Nf6 c4 e6 g3"},
+	{"E11", "Bogo-Indian Defense", "d4 Nf6 c4 e6 Nf3 Bb4+"},
+	{"E12", "Queen's Indian Defense", "d4 Nf6 c4 e6 Nf3 b6"},
+	{"E20", "Nimzo-Indian Defense", "d4 Nf6 c4 e6 Nc3 Bb4"},
+	{"E32", "Nimzo-Indian Defense: Classical Variation", "d4 Nf6 c4 e6 Nc3 Bb4 Qc2"},
+	{"E40", "Nimzo-Indian Defense: Rubinstein System", "d4 Nf6 c4 e6 Nc3 Bb4 e3"},
+	{"E60", "King's Indian Defense", "d4 Nf6 c4 g6"},
+	{"E61", "King's Indian Defense", "d4 Nf6 c4 g6 Nc3 Bg7"},
+	{"E70", "King's Indian Defense: Normal Variation", "d4 Nf6 c4 g6 Nc3 Bg7 e4 d6"},
+	{"E76", "King's Indian Defense: Four Pawns Attack", "d4 Nf6 c4 g6 Nc3 Bg7 e4 d6 f4"},
+	{"E80", "King's Indian Defense: Sämisch Variation", "d4 Nf6 c4 g6 Nc3 Bg7 e4 d6 f3"},
+}
+
+var (
+	// openingIndex maps the PositionKey at the end of each line in ecoTable to its opening, see loadOpenings
+	openingIndex map[string]Opening
+	openingOnce  sync.Once
+)
+
+// PositionKey identifies the position for recognizing openings: the FEN without the en passant square and move
+// counters. Leaving out the en passant square lets 1.e4 e5 2.Nf3 match whether or not a pawn could be taken.
+func (g *Game) PositionKey() string {
+	return strings.Join(strings.Fields(g.FEN())[:3], " ")
+}
+
+// openingPosition plays a line of SAN moves on a game of its own from the starting position, and returns the
+// PositionKey it ends in
+func openingPosition(moves string) (string, error) {
+	scratch := &Game{}
+	if err := scratch.LoadFEN(StartingFEN); err != nil {
+		return "", err
+	}
+	for _, san := range strings.Fields(moves) {
+		move, err := scratch.ParseMove(san)
+		if err != nil {
+			return "", fmt.Errorf("can't play %s: %v", san, err)
+		}
+		if !scratch.MakeMoveIfLegal(move) {
+			return "", errors.New("can't play " + san)
+		}
+	}
+	return scratch.PositionKey(), nil
+}
+
+// loadOpenings plays out each line of ecoTable to fill in openingIndex. A line that can't be played is a mistake in
+// the table, so it stops the program rather than quietly leaving the opening out.
+func loadOpenings() {
+	openingIndex = make(map[string]Opening, len(ecoTable))
+	for _, entry := range ecoTable {
+		key, err := openingPosition(entry.moves)
+		if err != nil {
+			log.Fatalf("opening %s %s: %v", entry.eco, entry.name, err)
+		}
+		openingIndex[key] = Opening{ECO: entry.eco, Name: entry.name}
+	}
+}
+
+// LookupOpening returns the opening the current position belongs to, or false if it isn't in ecoTable
+func (g *Game) LookupOpening() (Opening, bool) {
+	openingOnce.Do(loadOpenings)
+	opening, ok := openingIndex[g.PositionKey()]
+	return opening, ok
+}
+
+// UpdateOpening names the opening of the current position. Once the game leaves the table, the last opening it
+// reached is kept, so the name says which opening the game came out of.
+func (g *Game) UpdateOpening() {
+	if opening, ok := g.LookupOpening(); ok {
+		g.opening = opening
+	}
+}
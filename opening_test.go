@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// playMoves plays SAN moves the way they are typed into the game
+func playMoves(t *testing.T, g *Game, moves ...string) {
+	t.Helper()
+	for _, san := range moves {
+		if err := g.MakeMoveFromNotation(san); err != nil {
+			t.Fatalf("MakeMoveFromNotation(%q): %v", san, err)
+		}
+	}
+}
+
+// TestECOTable replays every line of ecoTable, and checks that no two lines end in the same position, where the
+// later one would hide the earlier
+func TestECOTable(t *testing.T) {
+	seen := make(map[string]string, len(ecoTable))
+	for _, entry := range ecoTable {
+		name := entry.eco + " " + entry.name
+		key, err := openingPosition(entry.moves)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if other, ok := seen[key]; ok {
+			t.Errorf("%s ends in the same position as %s", name, other)
+		}
+		seen[key] = name
+	}
+}
+
+func TestLookupOpening(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves string
+		want  string // "" when the game hasn't reached a named opening
+	}{
+		{"start", "", ""},
+		{"first move", "e4", "B00 King's Pawn Game"},
+		{"main line", "d4 d5 Nf3", "D02 Queen's Pawn Game: Zukertort Variation"},
+		{"transposition", "Nf3 d5 d4", "D02 Queen's Pawn Game: Zukertort Variation"},
+		{"later move", "e4 e5 Nf3", "C40 King's Knight Opening"},
+		{"out of the table keeps the last opening", "Nf3 d5 d4 a5 a4", "D02 Queen's Pawn Game: Zukertort Variation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, StartingFEN)
+			playMoves(t, g, strings.Fields(tt.moves)...)
+			got := ""
+			if g.opening.ECO != "" {
+				got = g.opening.String()
+			}
+			if got != tt.want {
+				t.Errorf("opening after %q = %q, want %q", tt.moves, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPGNOpeningTags(t *testing.T) {
+	g := loadTestGame(t, StartingFEN)
+	g.result = GameResult{Score: "*"}
+	playMoves(t, g, "e4", "e5", "Nf3", "Nc6", "Bb5")
+	pgn := g.PGN()
+	for _, tag := range []string{`[ECO "C60"]`, `[Opening "Ruy Lopez"]`} {
+		if !strings.Contains(pgn, tag+"\n") {
+			t.Errorf("PGN() doesn't have the tag %s:\n%s", tag, pgn)
+		}
+	}
+
+	g = loadTestGame(t, StartingFEN)
+	g.result = GameResult{Score: "*"}
+	playMoves(t, g, "a3")
+	if pgn := g.PGN(); strings.Contains(pgn, "[ECO ") || strings.Contains(pgn, "[Opening ") {
+		t.Errorf("PGN() of a game without a named opening has opening tags:\n%s", pgn)
+	}
+}
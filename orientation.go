@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"image/color"
+	"strconv"
+)
+
+// BoardFlipped reports whether the board is drawn from black's side. With autoFlip on, a local match turns the board
+// to face the side to move, and the Flip Board button turns it back around. With autoFlip off, only the button
+// decides.
+func (g *Game) BoardFlipped() bool {
+	facingBlack := g.autoFlip && !g.whitesTurn && g.gameType == 1
+	return facingBlack != g.boardFlipped
+}
+
+// FlipBoard turns the board around to the other side
+func (g *Game) FlipBoard() {
+	g.boardFlipped = !g.boardFlipped
+	g.scheduleDraw = true
+}
+
+// ToggleAutoFlip switches turning the board every move on or off, without changing which way the board faces now
+func (g *Game) ToggleAutoFlip() {
+	flipped := g.BoardFlipped()
+	g.autoFlip = !g.autoFlip
+	if g.BoardFlipped() != flipped {
+		g.boardFlipped = !g.boardFlipped
+	}
+}
+
+// DrawCoordinates labels the files along the bottom edge of the board and the ranks along the left edge, inside
+// the corner of each square. The labels follow the board when it is flipped.
+func (g *Game) DrawCoordinates() {
+	bottomRow, leftCol := 7, 0
+	if g.BoardFlipped() {
+		bottomRow, leftCol = 0, 7
+	}
+
+	// label offsets are in board space, scaled with the board
+	scale := g.layout.BoardScale()
+	tile := TileSize * scale
+
+	for i := 0; i < 8; i++ {
+		// file letter in the bottom right corner
+		x, y := g.SquareCenter(bottomRow, i)
+		text.Draw(g.uiImage, string(rune('a'+i)), g.uiFontSmall, int(x+tile/2-20*scale), int(y+tile/2-6*scale), coordinateColor(bottomRow, i))
+
+		// rank number in the top left corner
+		x, y = g.SquareCenter(i, leftCol)
+		text.Draw(g.uiImage, strconv.Itoa(8-i), g.uiFontSmall, int(x-tile/2+6*scale), int(y-tile/2+21*scale), coordinateColor(i, leftCol))
+	}
+}
+
+// coordinateColor is the color of the opposite square, so a label stands out from the square it is drawn on
+func coordinateColor(row, col int) color.Color {
+	if (row+col)%2 != 0 {
+		return lightSquareColor
+	}
+	return darkSquareColor
+}
@@ -9,42 +9,44 @@ type Pawn struct {
 }
 
 // Moves returns a slice of all possible moves (may include invalid moves) for given Piece. Each valid move in the slice is stored
-// in an array with a length of two-- Row and Col.
-func (p *Pawn) Moves(g Game) [][2]int {
-	moves := make([][2]int, 0)
+// as a Move, with the flags and captured piece filled in.
+func (p *Pawn) Moves(g Game) []Move {
+	moves := make([]Move, 0)
 
 	if p.white {
 		// white pawn on starting position, so could move forward one or two
 		// not checking bounds because there's no way to move out of bounds with hardcoded moves (I hope!)
 		if p.row == 6 {
-			if GetPieceOnSquare(5, p.col, g.pieces) == nil {
-				moves = append(moves, [2]int{5, p.col})
+			if g.PieceOn(5, p.col) == nil {
+				moves = appendPawnMove(moves, g.NewMove(p, 5, p.col))
 
 				//nested check to ensure we don't jump over a piece
-				if GetPieceOnSquare(4, p.col, g.pieces) == nil {
-					moves = append(moves, [2]int{4, p.col})
+				if g.PieceOn(4, p.col) == nil {
+					doublePush := g.NewMove(p, 4, p.col)
+					doublePush.Flags |= FlagDoublePush
+					moves = append(moves, doublePush)
 				}
 			}
 		} else {
 			// white pawn not on starting position
 			// now we check bounds
-			if GetPieceOnSquare(p.row-1, p.col, g.pieces) == nil && IsInBounds(p.row-1, p.col) {
-				moves = append(moves, [2]int{p.row - 1, p.col})
+			if g.PieceOn(p.row-1, p.col) == nil && IsInBounds(p.row-1, p.col) {
+				moves = appendPawnMove(moves, g.NewMove(p, p.row-1, p.col))
 			}
 		}
 
 		//now checking for takes
 		if IsInBounds(p.row-1, p.col+1) {
-			otherPiece := GetPieceOnSquare(p.row-1, p.col+1, g.pieces)
+			otherPiece := g.PieceOn(p.row-1, p.col+1)
 			if otherPiece != nil && otherPiece.White() != p.white {
-				moves = append(moves, [2]int{p.row - 1, p.col + 1})
+				moves = appendPawnMove(moves, g.NewMove(p, p.row-1, p.col+1))
 			}
 		}
 
 		if IsInBounds(p.row-1, p.col-1) {
-			otherPiece := GetPieceOnSquare(p.row-1, p.col-1, g.pieces)
+			otherPiece := g.PieceOn(p.row-1, p.col-1)
 			if otherPiece != nil && otherPiece.White() != p.white {
-				moves = append(moves, [2]int{p.row - 1, p.col - 1})
+				moves = appendPawnMove(moves, g.NewMove(p, p.row-1, p.col-1))
 			}
 		}
 
@@ -52,19 +54,25 @@ func (p *Pawn) Moves(g Game) [][2]int {
 		if p.row == 3 {
 
 			if IsInBounds(p.row, p.col+1) {
-				otherPiece := GetPieceOnSquare(p.row, p.col+1, g.pieces)
+				otherPiece := g.PieceOn(p.row, p.col+1)
 				if otherPiece != nil && otherPiece.White() != p.white {
 					if otherPiece.Row() == g.enPassantLocation[0] && otherPiece.Col() == g.enPassantLocation[1] {
-						moves = append(moves, [2]int{p.row - 1, p.col + 1})
+						enPassant := g.NewMove(p, p.row-1, p.col+1)
+						enPassant.Captured = otherPiece
+						enPassant.Flags |= FlagCapture | FlagEnPassant
+						moves = append(moves, enPassant)
 					}
 				}
 			}
 
 			if IsInBounds(p.row, p.col-1) {
-				otherPiece := GetPieceOnSquare(p.row, p.col-1, g.pieces)
+				otherPiece := g.PieceOn(p.row, p.col-1)
 				if otherPiece != nil && otherPiece.White() != p.white {
 					if otherPiece.Row() == g.enPassantLocation[0] && otherPiece.Col() == g.enPassantLocation[1] {
-						moves = append(moves, [2]int{p.row - 1, p.col - 1})
+						enPassant := g.NewMove(p, p.row-1, p.col-1)
+						enPassant.Captured = otherPiece
+						enPassant.Flags |= FlagCapture | FlagEnPassant
+						moves = append(moves, enPassant)
 					}
 				}
 			}
@@ -73,32 +81,34 @@ func (p *Pawn) Moves(g Game) [][2]int {
 	} else {
 		// black pawn on starting position, so could move forward one or two
 		if p.row == 1 {
-			if GetPieceOnSquare(2, p.col, g.pieces) == nil {
-				moves = append(moves, [2]int{2, p.col})
+			if g.PieceOn(2, p.col) == nil {
+				moves = appendPawnMove(moves, g.NewMove(p, 2, p.col))
 
-				if GetPieceOnSquare(3, p.col, g.pieces) == nil {
-					moves = append(moves, [2]int{3, p.col})
+				if g.PieceOn(3, p.col) == nil {
+					doublePush := g.NewMove(p, 3, p.col)
+					doublePush.Flags |= FlagDoublePush
+					moves = append(moves, doublePush)
 				}
 			}
 		} else {
 			// black pawn not on starting position
-			if GetPieceOnSquare(p.row+1, p.col, g.pieces) == nil && IsInBounds(p.row+1, p.col) {
-				moves = append(moves, [2]int{p.row + 1, p.col})
+			if g.PieceOn(p.row+1, p.col) == nil && IsInBounds(p.row+1, p.col) {
+				moves = appendPawnMove(moves, g.NewMove(p, p.row+1, p.col))
 			}
 		}
 
 		//now checking for takes
 		if IsInBounds(p.row+1, p.col+1) {
-			otherPiece1 := GetPieceOnSquare(p.row+1, p.col+1, g.pieces)
+			otherPiece1 := g.PieceOn(p.row+1, p.col+1)
 			if otherPiece1 != nil && otherPiece1.White() != p.white {
-				moves = append(moves, [2]int{p.row + 1, p.col + 1})
+				moves = appendPawnMove(moves, g.NewMove(p, p.row+1, p.col+1))
 			}
 		}
 
 		if IsInBounds(p.row+1, p.col-1) {
-			otherPiece2 := GetPieceOnSquare(p.row+1, p.col-1, g.pieces)
+			otherPiece2 := g.PieceOn(p.row+1, p.col-1)
 			if otherPiece2 != nil && otherPiece2.White() != p.white {
-				moves = append(moves, [2]int{p.row + 1, p.col - 1})
+				moves = appendPawnMove(moves, g.NewMove(p, p.row+1, p.col-1))
 			}
 		}
 
@@ -106,19 +116,25 @@ func (p *Pawn) Moves(g Game) [][2]int {
 		if p.row == 4 {
 
 			if IsInBounds(p.row, p.col+1) {
-				otherPiece := GetPieceOnSquare(p.row, p.col+1, g.pieces)
+				otherPiece := g.PieceOn(p.row, p.col+1)
 				if otherPiece != nil && otherPiece.White() != p.white {
 					if otherPiece.Row() == g.enPassantLocation[0] && otherPiece.Col() == g.enPassantLocation[1] {
-						moves = append(moves, [2]int{p.row + 1, p.col + 1})
+						enPassant := g.NewMove(p, p.row+1, p.col+1)
+						enPassant.Captured = otherPiece
+						enPassant.Flags |= FlagCapture | FlagEnPassant
+						moves = append(moves, enPassant)
 					}
 				}
 			}
 
 			if IsInBounds(p.row, p.col-1) {
-				otherPiece := GetPieceOnSquare(p.row, p.col-1, g.pieces)
+				otherPiece := g.PieceOn(p.row, p.col-1)
 				if otherPiece != nil && otherPiece.White() != p.white {
 					if otherPiece.Row() == g.enPassantLocation[0] && otherPiece.Col() == g.enPassantLocation[1] {
-						moves = append(moves, [2]int{p.row + 1, p.col - 1})
+						enPassant := g.NewMove(p, p.row+1, p.col-1)
+						enPassant.Captured = otherPiece
+						enPassant.Flags |= FlagCapture | FlagEnPassant
+						moves = append(moves, enPassant)
 					}
 				}
 			}
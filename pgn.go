@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PGNDir is where SavePGN writes games, relative to the working directory like the images folder
+const PGNDir = "games"
+
+// RecordMove adds a move that was just made to the game record, ex. "Nf3", with "+" when it gave check.
+// EndGame turns the "+" of a mating move into "#". The opening is named from the position the move reached.
+func (g *Game) RecordMove(san string) {
+	if g.inCheck {
+		san += "+"
+	}
+	g.moves = append(g.moves, san)
+	g.UpdateOpening()
+}
+
+// PGN writes the game as PGN. The annotations on the board are kept as a comment after the last move, and the
+// result and its termination come from g.result. Games that reached a named opening get ECO and Opening tags. Games
+// set up in the board editor get SetUp and FEN tags, and their moves are numbered on from the FEN.
+func (g *Game) PGN() string {
+	white, black := "Player 1", "Player 2"
+	if g.colorsSwapped {
+		white, black = black, white
+	}
+
+	var b strings.Builder
+	tags := [][2]string{
+		{"Event", "Local Match"},
+		{"Site", "?"},
+		{"Date", g.startTime.Format("2006.01.02")},
+		{"Round", "-"},
+		{"White", white},
+		{"Black", black},
+		{"Result", g.result.Score},
+		{"Termination", g.result.PGNTermination()},
+	}
+	if g.opening.ECO != "" {
+		tags = append(tags, [2]string{"ECO", g.opening.ECO}, [2]string{"Opening", g.opening.Name})
+	}
+	if g.startFEN != "" {
+		tags = append(tags, [2]string{"SetUp", "1"}, [2]string{"FEN", g.startFEN})
+	}
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "[%s %q]\n", tag[0], tag[1])
+	}
+	b.WriteString("\n")
+
+	var tokens []string
+	for i, san := range g.moves {
+		ply := g.plyOffset + i
+		if ply%2 == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d.", ply/2+1))
+		} else if i == 0 {
+			// black moved first
+			tokens = append(tokens, fmt.Sprintf("%d...", ply/2+1))
+		}
+		tokens = append(tokens, san)
+	}
+	if comment := g.AnnotationComment(); comment != "" {
+		tokens = append(tokens, "{"+comment+"}")
+	}
+	tokens = append(tokens, g.result.Score)
+
+	// PGN export format keeps lines under 80 characters
+	for _, line := range wrapText(strings.Join(tokens, " "), 79) {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// SavePGN writes the game to a new file in PGNDir, named after when the game started, and returns its path
+func (g *Game) SavePGN() (string, error) {
+	if err := os.MkdirAll(PGNDir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(PGNDir, "chess-"+g.startTime.Format("20060102-150405")+".pgn")
+	return path, os.WriteFile(path, []byte(g.PGN()), 0644)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPGN(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string // "" for the usual start
+		moves    []string
+		finish   func(t *testing.T, g *Game)
+		tags     []string
+		movetext string
+	}{
+		{
+			name:  "checkmate",
+			moves: []string{"f3", "e5", "g4", "Qh4"},
+			finish: func(t *testing.T, g *Game) {
+				g.ToggleAnnotation(Annotation{colorCode: 'R', fromRow: 4, fromCol: 6, toRow: 4, toCol: 6})
+				g.ToggleAnnotation(Annotation{colorCode: 'G', fromRow: 4, fromCol: 7, toRow: 7, toCol: 4})
+				g.IsCheckmate()
+			},
+			tags:     []string{`[Result "0-1"]`, `[Termination "normal"]`},
+			movetext: "1. f3 e5 2. g4 Qh4# {[%csl Rg4][%cal Gh4e1]} 0-1",
+		},
+		{
+			name:     "black to move from a FEN",
+			fen:      "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12",
+			moves:    []string{"Kd7", "e4", "Kd6"},
+			finish:   func(t *testing.T, g *Game) {},
+			tags:     []string{`[Result "*"]`, `[Termination "unterminated"]`, `[SetUp "1"]`, `[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12"]`},
+			movetext: "12... Kd7 13. e4 Kd6 *",
+		},
+		{
+			name:  "resignation",
+			moves: []string{"e4", "e5", "Bc4", "Nc6", "Bxf7"},
+			finish: func(t *testing.T, g *Game) {
+				g.ResignOrAbort()
+				clickDialogButton(t, g, "Black")
+			},
+			tags:     []string{`[Result "1-0"]`, `[Termination "normal"]`},
+			movetext: "1. e4 e5 2. Bc4 Nc6 3. Bxf7+ 1-0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fen := StartingFEN
+			if tt.fen != "" {
+				fen = tt.fen
+			}
+			g := loadTestGame(t, fen)
+			g.startFEN = tt.fen
+			g.gameUI = newTestUI()
+			g.result = GameResult{Score: "*"}
+			playMoves(t, g, tt.moves...)
+			tt.finish(t, g)
+
+			pgn := g.PGN()
+			header, movetext, _ := strings.Cut(pgn, "\n\n")
+			for _, tag := range tt.tags {
+				if !strings.Contains(header+"\n", tag+"\n") {
+					t.Errorf("PGN() doesn't have the tag %s:\n%s", tag, pgn)
+				}
+			}
+			if tt.fen == "" && (strings.Contains(header, "[SetUp ") || strings.Contains(header, "[FEN ")) {
+				t.Errorf("PGN() of a game from the usual start has SetUp or FEN tags:\n%s", pgn)
+			}
+			if got := strings.TrimSpace(movetext); got != tt.movetext {
+				t.Errorf("PGN() movetext = %q, want %q", got, tt.movetext)
+			}
+		})
+	}
+}
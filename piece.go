@@ -26,7 +26,7 @@ type ChessPiece interface {
 	SetRow(int)
 	White() bool
 	Image() *ebiten.Image
-	Moves(Game) [][2]int
+	Moves(Game) []Move
 	Name() string
 }
 
@@ -62,15 +62,30 @@ func IsInBounds(row int, col int) bool {
 	return row <= 7 && row >= 0 && col <= 7 && col >= 0
 }
 
-// GetPieceOnSquare checks list of ChessPiece against provided row and col positions. If a match
-// is found, a copy of that piece is returned. Otherwise, returns nil.
-func GetPieceOnSquare(row int, col int, pieces [32]ChessPiece) ChessPiece {
-	for _, piece := range pieces {
-		if piece.Col() == col && piece.Row() == row {
-			return piece
-		}
+// ClonePiece returns a copy of piece that can be moved around without affecting the original
+func ClonePiece(piece ChessPiece) ChessPiece {
+	switch p := piece.(type) {
+	case *Pawn:
+		clone := *p
+		return &clone
+	case *Knight:
+		clone := *p
+		return &clone
+	case *Bishop:
+		clone := *p
+		return &clone
+	case *Rook:
+		clone := *p
+		return &clone
+	case *Queen:
+		clone := *p
+		return &clone
+	case *King:
+		clone := *p
+		return &clone
+	default:
+		return nil
 	}
-	return nil
 }
 
 // GetWeighting is implemented so that we can sort pieces by value for UI purposes
@@ -91,3 +106,53 @@ func GetWeighting(piece ChessPiece) int {
 		return 0
 	}
 }
+
+// MaterialValue is the usual point value of a piece, ex. 3 for a knight. Kings are not counted.
+func MaterialValue(piece ChessPiece) int {
+	switch piece.Name()[6:] {
+	case "pawn":
+		return 1
+	case "knight", "bishop":
+		return 3
+	case "rook":
+		return 5
+	case "queen":
+		return 9
+	default:
+		return 0
+	}
+}
+
+// NewPiece creates a piece from its FEN letter, ex. 'N' for a knight, at row, col. The case of the letter is ignored,
+// so white decides the team. Returns nil if letter isn't a piece.
+func NewPiece(letter byte, row, col int, white bool) ChessPiece {
+	p := Piece{row, col, white}
+	switch letter {
+	case 'K', 'k':
+		return &King{p}
+	case 'Q', 'q':
+		return &Queen{p}
+	case 'R', 'r':
+		return &Rook{p}
+	case 'B', 'b':
+		return &Bishop{p}
+	case 'N', 'n':
+		return &Knight{p}
+	case 'P', 'p':
+		return &Pawn{p}
+	default:
+		return nil
+	}
+}
+
+// PieceLetter is the inverse of NewPiece, the FEN letter of piece, ex. 'N' for a white knight and 'n' for a black one
+func PieceLetter(piece ChessPiece) byte {
+	letter := byte('P')
+	if l, ok := sanPieceLetters[piece.Name()[6:]]; ok {
+		letter = l[0]
+	}
+	if !piece.White() {
+		letter += 'a' - 'A'
+	}
+	return letter
+}
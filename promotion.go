@@ -0,0 +1,35 @@
+package main
+
+import "strings"
+
+// DropPiece plays piece, let go of on row, col by the mouse or keyboard. A pawn dropped on the last rank asks which
+// piece to promote to before the move is made, see ChoosePromotion.
+func (g *Game) DropPiece(piece ChessPiece, row, col int) {
+	move := MoveTo(piece, row, col)
+	if move.Promotion == 0 || !g.IsLegalMove(move) {
+		g.PlayMove(move, true)
+		return
+	}
+	// the pawn goes back to its square until a piece is chosen
+	g.Animate(piece, g.selectedLocation[0], g.selectedLocation[1], false, SnapBackAnimDuration)
+	g.ChoosePromotion(move)
+}
+
+// ChoosePromotion opens a dialog with a button for each piece in PromotionLetters, and plays move promoting to the
+// one chosen. The pawn waits on its square meanwhile, and stays there if Escape cancels the move.
+func (g *Game) ChoosePromotion(move Move) {
+	var d *Dialog
+	buttons := make([]*Button, len(PromotionLetters))
+	for i := 0; i < len(PromotionLetters); i++ {
+		letter := PromotionLetters[i]
+		kind := NewPiece(letter, 0, 0, true).Name()[6:]
+		buttons[i] = NewButton(strings.ToUpper(kind[:1])+kind[1:], func() {
+			g.gameUI.CloseDialog(d)
+			move.Promotion = letter
+			g.PlayMove(move, false)
+		})
+	}
+	d = NewDialog("Promotion", "Choose a piece for the pawn on "+SquareName(move.To[0], move.To[1])+".", buttons...)
+	d.onCancel = func() {}
+	g.gameUI.ShowDialog(d)
+}
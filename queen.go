@@ -9,20 +9,20 @@ type Queen struct {
 }
 
 // Moves returns a slice of all valid moves for given Piece. Each valid move in the slice is stored
-// in an array with a length of two-- Row and Col.
-func (p *Queen) Moves(g Game) [][2]int {
-	moves := make([][2]int, 0)
+// as a Move, with the flags and captured piece filled in.
+func (p *Queen) Moves(g Game) []Move {
+	moves := make([]Move, 0)
 
 	//Queen can go any direction until it encounters a piece. If not on it's team, can take.
 	for col := p.col - 1; col >= 0; col-- {
-		otherPiece := GetPieceOnSquare(p.row, col, g.pieces)
+		otherPiece := g.PieceOn(p.row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{p.row, col})
+			moves = append(moves, g.NewMove(p, p.row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{p.row, col})
+				moves = append(moves, g.NewMove(p, p.row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -30,14 +30,14 @@ func (p *Queen) Moves(g Game) [][2]int {
 	}
 
 	for col := p.col + 1; col <= 7; col++ {
-		otherPiece := GetPieceOnSquare(p.row, col, g.pieces)
+		otherPiece := g.PieceOn(p.row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{p.row, col})
+			moves = append(moves, g.NewMove(p, p.row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{p.row, col})
+				moves = append(moves, g.NewMove(p, p.row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -45,14 +45,14 @@ func (p *Queen) Moves(g Game) [][2]int {
 	}
 
 	for row := p.row - 1; row >= 0; row-- {
-		otherPiece := GetPieceOnSquare(row, p.col, g.pieces)
+		otherPiece := g.PieceOn(row, p.col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, p.col})
+			moves = append(moves, g.NewMove(p, row, p.col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, p.col})
+				moves = append(moves, g.NewMove(p, row, p.col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -60,14 +60,14 @@ func (p *Queen) Moves(g Game) [][2]int {
 	}
 
 	for row := p.row + 1; row <= 7; row++ {
-		otherPiece := GetPieceOnSquare(row, p.col, g.pieces)
+		otherPiece := g.PieceOn(row, p.col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, p.col})
+			moves = append(moves, g.NewMove(p, row, p.col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, p.col})
+				moves = append(moves, g.NewMove(p, row, p.col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -85,14 +85,14 @@ func (p *Queen) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -109,14 +109,14 @@ func (p *Queen) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -134,14 +134,14 @@ func (p *Queen) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -159,14 +159,14 @@ func (p *Queen) Moves(g Game) [][2]int {
 			break
 		}
 
-		otherPiece := GetPieceOnSquare(row, col, g.pieces)
+		otherPiece := g.PieceOn(row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, col})
+			moves = append(moves, g.NewMove(p, row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, col})
+				moves = append(moves, g.NewMove(p, row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
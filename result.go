@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Termination is why a game ended
+type Termination int
+
+const (
+	TerminationNone Termination = iota
+	TerminationCheckmate
+	TerminationResignation
+	TerminationAgreement
+	TerminationAbort
+)
+
+// GameResult is how a game ended. Score is the result as PGN writes it: "1-0", "0-1", "1/2-1/2", or "*" for a
+// game that is still being played or was aborted.
+type GameResult struct {
+	Score  string
+	Reason Termination
+}
+
+// WinFor is the result of a game won by white (or black) for reason
+func WinFor(white bool, reason Termination) GameResult {
+	if white {
+		return GameResult{Score: "1-0", Reason: reason}
+	}
+	return GameResult{Score: "0-1", Reason: reason}
+}
+
+// DrawnBy is the result of a game drawn for reason
+func DrawnBy(reason Termination) GameResult {
+	return GameResult{Score: "1/2-1/2", Reason: reason}
+}
+
+// Description is a sentence about the result for the player, ex. "Checkmate, White wins!"
+func (r GameResult) Description() string {
+	winner := "White"
+	if r.Score == "0-1" {
+		winner = "Black"
+	}
+	switch r.Reason {
+	case TerminationCheckmate:
+		return "Checkmate, " + winner + " wins!"
+	case TerminationResignation:
+		return SideName(winner == "Black") + " resigns, " + winner + " wins!"
+	case TerminationAgreement:
+		return "Draw by agreement"
+	case TerminationAbort:
+		return "Game aborted"
+	}
+	return ""
+}
+
+// PGNTermination is the value of the PGN Termination tag for the result
+func (r GameResult) PGNTermination() string {
+	// an aborted game was called off before it got going, "abandoned" would mean a player walked away from it
+	switch r.Reason {
+	case TerminationNone, TerminationAbort:
+		return "unterminated"
+	}
+	return "normal"
+}
+
+// SideName is "White" or "Black"
+func SideName(white bool) string {
+	if white {
+		return "White"
+	}
+	return "Black"
+}
+
+// EndGame finishes the game with result and opens the game over dialog. The board stays as it is, but no more
+// moves can be made on it.
+func (g *Game) EndGame(result GameResult) {
+	if g.keyboardHeld {
+		g.DropKeyboardPiece(false)
+	}
+	if result.Reason == TerminationCheckmate && len(g.moves) > 0 {
+		last := len(g.moves) - 1
+		g.moves[last] = strings.TrimSuffix(g.moves[last], "+") + "#"
+	}
+	g.result = result
+	g.gameOver = true
+	g.endTime = time.Now()
+	g.gameOverMsg = result.Description()
+	g.emit(EventGameEnd)
+	g.ShowGameOver()
+}
+
+// MaterialBalance describes who is ahead on material, ex. "White +3", counting the pieces still on the board
+func (g *Game) MaterialBalance() string {
+	balance := 0
+	for _, piece := range g.pieces {
+		if piece.White() {
+			balance += MaterialValue(piece)
+		} else {
+			balance -= MaterialValue(piece)
+		}
+	}
+	switch {
+	case balance > 0:
+		return fmt.Sprintf("White +%d", balance)
+	case balance < 0:
+		return fmt.Sprintf("Black +%d", -balance)
+	}
+	return "even"
+}
+
+// FormatElapsed writes a length of time as minutes and seconds, ex. "12:05", with hours in front once there are any,
+// ex. "1:02:05"
+func FormatElapsed(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// ShowGameOver opens the end of game dialog with a summary of the game. Escape closes it to look at the final
+// position, and the New Game and Main Menu buttons beside the board still work.
+func (g *Game) ShowGameOver() {
+	summary := fmt.Sprintf("%s\nResult: %s\nMoves: %d\nTime: %s\nMaterial: %s",
+		g.result.Description(), g.result.Score, (len(g.moves)+1)/2, FormatElapsed(g.endTime.Sub(g.startTime)),
+		g.MaterialBalance())
+
+	var d *Dialog
+	d = NewDialog("Game over", summary,
+		NewButton("Rematch", func() {
+			g.gameUI.CloseDialog(d)
+			g.Rematch()
+		}),
+		NewButton("Save PGN", func() {
+			g.gameUI.CloseDialog(d)
+			path, err := g.SavePGN()
+			message := "Saved to " + path
+			if err != nil {
+				message = "Couldn't save the game: " + err.Error()
+			}
+			var saved *Dialog
+			saved = NewDialog("Save PGN", message, NewButton("OK", func() {
+				g.gameUI.CloseDialog(saved)
+				g.ShowGameOver()
+			}))
+			saved.onCancel = g.ShowGameOver
+			g.gameUI.ShowDialog(saved)
+		}),
+		NewButton("Main Menu", func() {
+			g.gameUI.CloseDialog(d)
+			g.gameType = -1
+		}))
+	d.buttons[2].primary = true
+	d.onCancel = func() {}
+	g.gameUI.ShowDialog(d)
+}
+
+// Rematch starts a new game with the players swapping colors. The board turns around too, so each player stays on
+// their side of the screen. A game set up in the board editor is replayed from the same position.
+func (g *Game) Rematch() {
+	g.colorsSwapped = !g.colorsSwapped
+	g.boardFlipped = !g.boardFlipped
+	if g.startFEN == "" {
+		g.InitPiecesAndImages()
+		return
+	}
+	if err := g.LoadFEN(g.startFEN); err != nil {
+		log.Fatal(err)
+	}
+	g.StartGame()
+}
+
+// CanAbort reports whether the game can still be called off without a result, which is until both sides have moved
+func (g *Game) CanAbort() bool {
+	return !g.gameOver && g.moveNum < 2
+}
+
+// ResignOrAbort is the action of resignButton. Before both sides have moved, the game is aborted instead. Both players
+// share the board in a local match, so either side can resign, whoever's move it is.
+func (g *Game) ResignOrAbort() {
+	if g.CanAbort() {
+		g.confirm("Abort game?", "The game will end without a result.", "Abort", func() {
+			g.EndGame(GameResult{Score: "*", Reason: TerminationAbort})
+		})
+		return
+	}
+
+	var d *Dialog
+	resign := func(white bool) *Button {
+		return NewButton(SideName(white), func() {
+			g.gameUI.CloseDialog(d)
+			g.EndGame(WinFor(!white, TerminationResignation))
+		})
+	}
+	// the side to move is offered first
+	d = NewDialog("Resign?", "Which side resigns? The other side wins the game.",
+		resign(g.whitesTurn), resign(!g.whitesTurn),
+		NewButton("Cancel", func() {
+			g.gameUI.CloseDialog(d)
+		}))
+	d.onCancel = func() {}
+	g.gameUI.ShowDialog(d)
+}
+
+// OfferDraw is the action of drawButton. Once the side to move confirms the offer, the other side is asked whether
+// to accept it. Both players share the screen in a local match, so the offer is made with a dialog.
+func (g *Game) OfferDraw() {
+	offering := g.whitesTurn
+	g.confirm("Offer a draw?", SideName(!offering)+" will be asked to accept or decline.", "Offer", func() {
+		var offer *Dialog
+		offer = NewDialog("Draw offered", SideName(offering)+" offers a draw. "+SideName(!offering)+", do you accept?",
+			NewButton("Accept", func() {
+				g.gameUI.CloseDialog(offer)
+				g.EndGame(DrawnBy(TerminationAgreement))
+			}),
+			NewButton("Decline", func() {
+				g.gameUI.CloseDialog(offer)
+			}))
+		offer.onCancel = func() {}
+		g.gameUI.ShowDialog(offer)
+	})
+}
+
+// confirm asks the player to confirm an action with a dialog, calling onConfirm if they do. Cancel or Escape close
+// the dialog without doing anything.
+func (g *Game) confirm(title, message, action string, onConfirm func()) {
+	var d *Dialog
+	d = NewDialog(title, message,
+		NewButton(action, func() {
+			g.gameUI.CloseDialog(d)
+			onConfirm()
+		}),
+		NewButton("Cancel", func() {
+			g.gameUI.CloseDialog(d)
+		}))
+	d.onCancel = func() {}
+	g.gameUI.ShowDialog(d)
+}
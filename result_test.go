@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// clickDialogButton clicks the button labelled text in the dialog on top of gameUI
+func clickDialogButton(t *testing.T, g *Game, text string) {
+	t.Helper()
+	if !g.gameUI.Modal() {
+		t.Fatalf("no dialog is open to click %q in", text)
+	}
+	d := g.gameUI.dialogs[len(g.gameUI.dialogs)-1]
+	for _, b := range d.buttons {
+		if b.text == text {
+			b.click()
+			return
+		}
+	}
+	t.Fatalf("dialog %q has no %q button", d.title, text)
+}
+
+func TestPGNTermination(t *testing.T) {
+	tests := []struct {
+		result GameResult
+		want   string
+	}{
+		{GameResult{Score: "*"}, "unterminated"},
+		{GameResult{Score: "*", Reason: TerminationAbort}, "unterminated"},
+		{WinFor(true, TerminationCheckmate), "normal"},
+		{WinFor(false, TerminationResignation), "normal"},
+		{DrawnBy(TerminationAgreement), "normal"},
+	}
+	for _, tt := range tests {
+		if got := tt.result.PGNTermination(); got != tt.want {
+			t.Errorf("PGNTermination() of %+v = %q, want %q", tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestResignOrAbort(t *testing.T) {
+	tests := []struct {
+		name   string
+		moves  []string
+		button string
+		want   GameResult
+	}{
+		{"abort", []string{"e4"}, "Abort", GameResult{Score: "*", Reason: TerminationAbort}},
+		{"side to move resigns", []string{"e4", "e5"}, "White", WinFor(false, TerminationResignation)},
+		{"other side resigns", []string{"e4", "e5"}, "Black", WinFor(true, TerminationResignation)},
+		{"other side resigns after black's move", []string{"e4", "e5", "Nf3"}, "White", WinFor(false, TerminationResignation)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := loadTestGame(t, StartingFEN)
+			g.gameUI = newTestUI()
+			g.result = GameResult{Score: "*"}
+			playMoves(t, g, tt.moves...)
+
+			g.ResignOrAbort()
+			clickDialogButton(t, g, tt.button)
+			if !g.gameOver || g.result != tt.want {
+				t.Errorf("gameOver = %v, result = %+v, want true and %+v", g.gameOver, g.result, tt.want)
+			}
+		})
+	}
+
+	g := loadTestGame(t, StartingFEN)
+	g.gameUI = newTestUI()
+	g.result = GameResult{Score: "*"}
+	playMoves(t, g, "e4", "e5")
+	g.ResignOrAbort()
+	clickDialogButton(t, g, "Cancel")
+	if g.gameOver || g.gameUI.Modal() {
+		t.Errorf("Cancel left gameOver = %v and a dialog open = %v, want both false", g.gameOver, g.gameUI.Modal())
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00"},
+		{1499 * time.Millisecond, "0:01"},
+		{65 * time.Second, "1:05"},
+		{59*time.Minute + 59*time.Second + 600*time.Millisecond, "1:00:00"},
+		{3*time.Hour + 2*time.Minute + 5*time.Second, "3:02:05"},
+	}
+	for _, tt := range tests {
+		if got := FormatElapsed(tt.d); got != tt.want {
+			t.Errorf("FormatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
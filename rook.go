@@ -7,19 +7,19 @@ type Rook struct {
 }
 
 // Moves returns a slice of all valid moves for given Piece. Each valid move in the slice is stored
-// in an array with a length of two-- Row and Col.
-func (p *Rook) Moves(g Game) [][2]int {
-	moves := make([][2]int, 0)
+// as a Move, with the flags and captured piece filled in.
+func (p *Rook) Moves(g Game) []Move {
+	moves := make([]Move, 0)
 
 	for col := p.col - 1; col >= 0; col-- {
-		otherPiece := GetPieceOnSquare(p.row, col, g.pieces)
+		otherPiece := g.PieceOn(p.row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{p.row, col})
+			moves = append(moves, g.NewMove(p, p.row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{p.row, col})
+				moves = append(moves, g.NewMove(p, p.row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -27,14 +27,14 @@ func (p *Rook) Moves(g Game) [][2]int {
 	}
 
 	for col := p.col + 1; col <= 7; col++ {
-		otherPiece := GetPieceOnSquare(p.row, col, g.pieces)
+		otherPiece := g.PieceOn(p.row, col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{p.row, col})
+			moves = append(moves, g.NewMove(p, p.row, col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{p.row, col})
+				moves = append(moves, g.NewMove(p, p.row, col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -42,14 +42,14 @@ func (p *Rook) Moves(g Game) [][2]int {
 	}
 
 	for row := p.row - 1; row >= 0; row-- {
-		otherPiece := GetPieceOnSquare(row, p.col, g.pieces)
+		otherPiece := g.PieceOn(row, p.col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, p.col})
+			moves = append(moves, g.NewMove(p, row, p.col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, p.col})
+				moves = append(moves, g.NewMove(p, row, p.col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
@@ -57,14 +57,14 @@ func (p *Rook) Moves(g Game) [][2]int {
 	}
 
 	for row := p.row + 1; row <= 7; row++ {
-		otherPiece := GetPieceOnSquare(row, p.col, g.pieces)
+		otherPiece := g.PieceOn(row, p.col)
 		if otherPiece == nil {
 			//No piece encountered, valid move
-			moves = append(moves, [2]int{row, p.col})
+			moves = append(moves, g.NewMove(p, row, p.col))
 		} else {
 			//Piece encountered... is it on the other team?
 			if otherPiece.White() != p.white {
-				moves = append(moves, [2]int{row, p.col})
+				moves = append(moves, g.NewMove(p, row, p.col))
 			}
 			// Can't go further in this loop / can't go further this direction on the board
 			break
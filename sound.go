@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"io"
+	"log"
+	"math"
+)
+
+//go:embed sounds/*.wav
+var soundFiles embed.FS
+
+const (
+	SampleRate = 44100
+	// VolumeStep is how much the volume keys change the volume by
+	VolumeStep = 0.1
+)
+
+// soundFileNames maps each GameEvent to the sound played for it
+var soundFileNames = map[GameEvent]string{
+	EventGameStart:   "sounds/start.wav",
+	EventMove:        "sounds/move.wav",
+	EventCapture:     "sounds/capture.wav",
+	EventCastle:      "sounds/castle.wav",
+	EventCheck:       "sounds/check.wav",
+	EventPromotion:   "sounds/promotion.wav",
+	EventIllegalMove: "sounds/illegal.wav",
+	EventGameEnd:     "sounds/end.wav",
+}
+
+// Sounds plays the embedded sound effects in response to game events.
+// volume ranges from 0 to 1, and muted silences everything without losing the volume setting.
+type Sounds struct {
+	context *audio.Context
+	samples map[GameEvent][]byte
+	volume  float64
+	muted   bool
+}
+
+// NewSounds decodes the embedded sound effects, ready to be played with Play
+func NewSounds() *Sounds {
+	s := &Sounds{
+		context: audio.NewContext(SampleRate),
+		samples: make(map[GameEvent][]byte),
+		volume:  0.8,
+	}
+
+	for event, name := range soundFileNames {
+		file, err := soundFiles.ReadFile(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		stream, err := wav.DecodeWithSampleRate(SampleRate, bytes.NewReader(file))
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.samples[event], err = io.ReadAll(stream)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	return s
+}
+
+// Play starts the sound for event. Sounds may overlap, ex. the end of game sound after a checking move.
+func (s *Sounds) Play(event GameEvent) {
+	sample, ok := s.samples[event]
+	if !ok || s.muted || s.volume == 0 {
+		return
+	}
+	player := s.context.NewPlayerFromBytes(sample)
+	player.SetVolume(s.volume)
+	player.Play()
+}
+
+// UpdateControls handles the sound hotkeys: M toggles mute, and - / = turn the volume down and up
+func (s *Sounds) UpdateControls() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		s.muted = !s.muted
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
+		s.volume = math.Max(0, s.volume-VolumeStep)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
+		s.volume = math.Min(1, s.volume+VolumeStep)
+	}
+}
+
+// Status is a short description of the sound settings for the UI, ex. "Sound 80%"
+func (s *Sounds) Status() string {
+	if s.muted {
+		return "Sound muted"
+	}
+	return fmt.Sprintf("Sound %d%%", int(math.Round(s.volume*100)))
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Texel tuning adjusts the evaluation weights so that the evaluation predicts the results of real games. Each
+// position from a dataset is labelled with the result of its game, and the evaluation is turned into an expected
+// result by a sigmoid. The weights are nudged one at a time, keeping any change that lowers the mean squared error
+// between the expected and actual results, until no change helps.
+
+// MinTuningPly skips the first plies of each game in a PGN dataset, which are mostly opening theory
+const MinTuningPly = 8
+
+// tuningPosition is a position from the dataset, kept as the terms of its evaluation so it can be scored with new
+// weights without replaying the game
+type tuningPosition struct {
+	result float64 // 1 if white won, 0.5 for a draw and 0 if black won
+	phase  int
+	base   [2]int // the middlegame and endgame totals of the terms whose weights aren't tuned
+	terms  []tuningTerm
+}
+
+// tuningTerm is one term of a position's evaluation: the index of the weight it uses, whether that is the
+// middlegame (0) or endgame (1) value, and how many times it applies
+type tuningTerm struct {
+	weight int
+	phase  int
+	count  int
+}
+
+// RunTuning tunes the weights in params on the positions in dataset and saves them to out after every pass, so
+// tuning can be stopped at any time. dataset is either a PGN file of finished games, or an EPD file with the result
+// of each position's game, ex. c9 "1-0" or [1.0].
+func RunTuning(dataset string, params *EvalParams, out string) error {
+	weights := params.weights()
+	index := make(map[*int]int, len(weights))
+	for i, w := range weights {
+		index[w] = i
+	}
+
+	var positions []tuningPosition
+	visit := func(g *Game, result float64) {
+		pos := tuningPosition{result: result, phase: g.GamePhase()}
+		g.evalTerms(params, func(weight *[2]int, count int) {
+			for i := range weight {
+				if w, ok := index[&weight[i]]; ok {
+					pos.terms = append(pos.terms, tuningTerm{w, i, count})
+				} else {
+					pos.base[i] += weight[i] * count
+				}
+			}
+		})
+		positions = append(positions, pos)
+	}
+
+	var err error
+	if strings.EqualFold(filepath.Ext(dataset), ".pgn") {
+		err = readPGNDataset(dataset, visit)
+	} else {
+		err = readEPDDataset(dataset, visit)
+	}
+	if err != nil {
+		return err
+	}
+	if len(positions) == 0 {
+		return errors.New("no positions to tune on in " + dataset)
+	}
+	log.Printf("tuning %d weights on %d positions", len(weights), len(positions))
+
+	meanError := func(k float64) float64 {
+		total := 0.0
+		for _, pos := range positions {
+			sums := pos.base
+			for _, t := range pos.terms {
+				sums[t.phase] += *weights[t.weight] * t.count
+			}
+			score := float64(blendPhase(sums[0], sums[1], pos.phase))
+			expected := 1 / (1 + math.Pow(10, -k*score/400))
+			total += (pos.result - expected) * (pos.result - expected)
+		}
+		return total / float64(len(positions))
+	}
+
+	// K scales centipawns to the expected result. It is fitted to the starting weights and then held still, so the
+	// weights can't all shrink or grow together to chase it.
+	k := 1.0
+	best := meanError(k)
+	for _, step := range []float64{0.1, 0.01, 0.001} {
+		for improved := true; improved; {
+			improved = false
+			for _, next := range []float64{k + step, k - step} {
+				if next <= 0 {
+					continue
+				}
+				if e := meanError(next); e < best {
+					k, best, improved = next, e, true
+					break
+				}
+			}
+		}
+	}
+	log.Printf("K = %.3f, error %.6f", k, best)
+
+	for pass, improved := 1, true; improved; pass++ {
+		improved = false
+		for _, w := range weights {
+			*w++
+			if e := meanError(k); e < best {
+				best, improved = e, true
+				continue
+			}
+			*w -= 2
+			if e := meanError(k); e < best {
+				best, improved = e, true
+				continue
+			}
+			*w++
+		}
+		if err := SaveEvalParams(params, out); err != nil {
+			return err
+		}
+		log.Printf("pass %d: error %.6f, saved to %s", pass, best, out)
+	}
+	return nil
+}
+
+// resultScore converts a game result to a score for white, ok is false for an unfinished game ("*")
+func resultScore(result string) (score float64, ok bool) {
+	switch result {
+	case "1-0", "1.0":
+		return 1, true
+	case "0-1", "0.0":
+		return 0, true
+	case "1/2-1/2", "0.5":
+		return 0.5, true
+	}
+	return 0, false
+}
+
+// readEPDDataset calls visit with each position in an EPD file and the result of its game. The result can be given
+// as a c9 opcode, ex. c9 "1/2-1/2";, or in brackets after the position, ex. [0.5]. Positions with the side to move in
+// check are skipped, since the evaluation only makes sense in quiet positions.
+func readEPDDataset(path string, visit func(g *Game, result float64)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		result := ""
+		if i := strings.Index(line, "c9 \""); i != -1 {
+			result, _, _ = strings.Cut(line[i+4:], "\"")
+		} else if i := strings.LastIndex(line, "["); i != -1 {
+			result = strings.TrimSuffix(line[i+1:], "]")
+		}
+		score, ok := resultScore(result)
+		if !ok {
+			return fmt.Errorf("%s:%d: no game result", path, lineNum)
+		}
+
+		g := &Game{}
+		if err := g.LoadFEN(strings.Join(fields[:4], " ")); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, lineNum, err)
+		}
+		if !g.KingInCheck(g.whitesTurn) {
+			visit(g, score)
+		}
+	}
+	return scanner.Err()
+}
+
+// readPGNDataset replays each finished game in a PGN file and calls visit with its positions and result. The
+// opening (see MinTuningPly), positions right after a capture or check, and games that can't be replayed are
+// skipped.
+func readPGNDataset(path string, visit func(g *Game, result float64)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	games, skipped := 0, 0
+	tags := map[string]string{}
+	var movetext strings.Builder
+	playGame := func() {
+		defer func() {
+			tags = map[string]string{}
+			movetext.Reset()
+		}()
+		score, ok := resultScore(tags["Result"])
+		if !ok {
+			return
+		}
+		g := &Game{}
+		fen := StartingFEN
+		if tags["FEN"] != "" {
+			fen = tags["FEN"]
+		}
+		if err := g.LoadFEN(fen); err != nil {
+			skipped++
+			return
+		}
+		for ply, san := range pgnMoves(movetext.String()) {
+			move, err := g.ParseMove(san)
+			if err != nil || !g.MakeMoveIfLegal(move) {
+				skipped++
+				return
+			}
+			if ply+1 >= MinTuningPly && !strings.Contains(san, "x") && !g.inCheck {
+				visit(g, score)
+			}
+		}
+		games++
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			// a tag after movetext starts the next game
+			if movetext.Len() > 0 {
+				playGame()
+			}
+			name, value, _ := strings.Cut(strings.Trim(line, "[]"), " ")
+			tags[name] = strings.Trim(value, "\"")
+			continue
+		}
+		movetext.WriteString(line + "\n")
+	}
+	playGame()
+
+	log.Printf("read %d games from %s, skipped %d that couldn't be replayed", games, path, skipped)
+	return nil
+}
+
+// pgnMoves picks the moves in SAN out of PGN movetext, dropping move numbers, comments, variations, annotation
+// glyphs and the result
+func pgnMoves(movetext string) []string {
+	var moves []string
+	depth := 0
+	inComment := false
+	for _, line := range strings.Split(movetext, "\n") {
+		// the rest of a line after ; is a comment
+		if i := strings.Index(line, ";"); i != -1 && !inComment {
+			line = line[:i]
+		}
+		line = strings.NewReplacer("{", " { ", "}", " } ", "(", " ( ", ")", " ) ").Replace(line)
+		for _, token := range strings.Fields(line) {
+			switch {
+			case inComment:
+				inComment = token != "}"
+			case token == "{":
+				inComment = true
+			case token == "(":
+				depth++
+			case token == ")":
+				depth--
+			case depth > 0 || strings.HasPrefix(token, "$"):
+			default:
+				// move numbers are attached to the move in some files, ex. 1.e4 or 12...Nf6
+				if i := strings.LastIndex(token, "."); i != -1 {
+					token = token[i+1:]
+				}
+				if _, ok := resultScore(token); token != "" && token != "*" && !ok {
+					moves = append(moves, token)
+				}
+			}
+		}
+	}
+	return moves
+}
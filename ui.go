@@ -0,0 +1,319 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font"
+	"image/color"
+)
+
+// Theme is what widgets are drawn with. Font and Scale follow the layout, see Game.Layout.
+type Theme struct {
+	Font         font.Face
+	Scale        float64
+	Primary      *ebiten.Image
+	PrimaryHover *ebiten.Image
+	Info         *ebiten.Image
+	InfoHover    *ebiten.Image
+}
+
+var (
+	widgetFillColor   = color.RGBA{R: 0x0b, G: 0x1f, B: 0x1d, A: 0xff}
+	widgetAccentColor = color.RGBA{R: 0x2e, G: 0x6b, B: 0x66, A: 0xff}
+)
+
+// Widget is a control on a UI screen. The UI does the hit-testing and focus tracking, and calls the widget's event
+// methods: Press when the left mouse button goes down on it, Drag every tick while the button is held, and Release
+// when it comes back up (inside reports whether the cursor is still over the widget). Key is called every tick while
+// the widget has keyboard focus.
+type Widget interface {
+	Bounds() Rect
+	SetBounds(r Rect)
+	Enabled() bool
+	Contains(x, y int) bool
+	Press(ui *UI, x, y int)
+	Drag(ui *UI, x, y int)
+	Release(ui *UI, x, y int, inside bool)
+	Scroll(ui *UI, dy float64)
+	Key(ui *UI)
+	Draw(dst *ebiten.Image, ui *UI)
+}
+
+// widgetBase is embedded by every widget for its rect and disabled state, and so widgets only need to implement
+// the events they care about
+type widgetBase struct {
+	rect     Rect
+	disabled bool
+}
+
+func (w *widgetBase) Bounds() Rect                          { return w.rect }
+func (w *widgetBase) SetBounds(r Rect)                      { w.rect = r }
+func (w *widgetBase) Enabled() bool                         { return !w.disabled }
+func (w *widgetBase) SetEnabled(enabled bool)               { w.disabled = !enabled }
+func (w *widgetBase) Contains(x, y int) bool                { return w.rect.Contains(x, y) }
+func (w *widgetBase) Press(ui *UI, x, y int)                {}
+func (w *widgetBase) Drag(ui *UI, x, y int)                 {}
+func (w *widgetBase) Release(ui *UI, x, y int, inside bool) {}
+func (w *widgetBase) Scroll(ui *UI, dy float64)             {}
+func (w *widgetBase) Key(ui *UI)                            {}
+
+// UI is a screen of widgets. Update and UpdateKeyboard are called every tick, and Draw every frame.
+//
+// hovered is the widget under the cursor, focused receives keyboard input, and active is the widget the left mouse
+// button went down on, which keeps receiving Drag until the button is released. While a dialog is open, only its
+// buttons receive input. popup is an open Dropdown, drawn over everything else and hit-tested first.
+// showFocus is true while the keyboard is being used to move between widgets, so the focused widget is highlighted.
+// boardFocus is true on screens where focus can rest outside the widgets (on the board), with focused set to nil.
+type UI struct {
+	theme      *Theme
+	widgets    []Widget
+	dialogs    []*Dialog
+	popup      *Dropdown
+	hovered    Widget
+	focused    Widget
+	active     Widget
+	showFocus  bool
+	boardFocus bool
+	screen     Rect
+	lastCursor [2]int
+}
+
+func NewUI(theme *Theme, boardFocus bool) *UI {
+	return &UI{theme: theme, boardFocus: boardFocus}
+}
+
+// Add puts widgets on the screen. Widgets are drawn in the order they were added, and Tab visits them in that order.
+func (ui *UI) Add(widgets ...Widget) {
+	ui.widgets = append(ui.widgets, widgets...)
+}
+
+// Resize is called when the screen size changes, so open dialogs can stay centered
+func (ui *UI) Resize(screen Rect) {
+	ui.screen = screen
+	for _, d := range ui.dialogs {
+		d.layout(ui)
+	}
+}
+
+// targets are the widgets that currently receive input
+func (ui *UI) targets() []Widget {
+	if len(ui.dialogs) > 0 {
+		return ui.dialogs[len(ui.dialogs)-1].widgets()
+	}
+	return ui.widgets
+}
+
+// widgetAt returns the topmost widget containing x, y, or nil
+func (ui *UI) widgetAt(x, y int) Widget {
+	if ui.popup != nil && (ui.popup.Contains(x, y) || ui.popup.popupContains(ui, x, y)) {
+		return ui.popup
+	}
+	targets := ui.targets()
+	for i := len(targets) - 1; i >= 0; i-- {
+		if targets[i].Contains(x, y) {
+			return targets[i]
+		}
+	}
+	return nil
+}
+
+// Update handles the mouse. Returns true when the UI has claimed the mouse this tick: the cursor is over a widget,
+// a widget is being dragged, or a dialog is open. The caller should then leave the mouse alone.
+func (ui *UI) Update() bool {
+	x, y := ebiten.CursorPosition()
+	if x != ui.lastCursor[0] || y != ui.lastCursor[1] {
+		ui.showFocus = false
+	}
+	ui.lastCursor[0], ui.lastCursor[1] = x, y
+
+	ui.hovered = ui.widgetAt(x, y)
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		// clicking anywhere other than an open dropdown closes it
+		if ui.popup != nil && ui.hovered != Widget(ui.popup) {
+			ui.popup = nil
+		}
+		if ui.hovered != nil && ui.hovered.Enabled() {
+			ui.active = ui.hovered
+			ui.focused = ui.hovered
+			ui.hovered.Press(ui, x, y)
+		} else if ui.hovered == nil && ui.boardFocus && !ui.Modal() {
+			ui.focused = nil
+		}
+	}
+
+	if ui.active != nil {
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			ui.active.Drag(ui, x, y)
+		} else {
+			active := ui.active
+			ui.active = nil
+			active.Release(ui, x, y, active.Contains(x, y))
+		}
+	}
+
+	if _, dy := ebiten.Wheel(); dy != 0 && ui.hovered != nil && ui.hovered.Enabled() {
+		ui.hovered.Scroll(ui, dy)
+	}
+
+	return ui.hovered != nil || ui.active != nil || ui.Modal()
+}
+
+// UpdateKeyboard moves focus with Tab and Shift+Tab, dismisses dialogs with Escape, and passes keys to the focused
+// widget. Returns true if focus moved.
+func (ui *UI) UpdateKeyboard() bool {
+	moved := false
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			ui.FocusNext(-1)
+		} else {
+			ui.FocusNext(1)
+		}
+		moved = true
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && ui.Modal() && ui.popup == nil {
+		d := ui.dialogs[len(ui.dialogs)-1]
+		if d.onCancel != nil {
+			ui.CloseDialog(d)
+			d.onCancel()
+			return moved
+		}
+	}
+
+	if ui.focused != nil && !moved {
+		ui.focused.Key(ui)
+	}
+	return moved
+}
+
+// FocusNext moves focus dir (1 or -1) widgets along, skipping disabled widgets. Focus passes through nil (the
+// board) on screens with boardFocus.
+func (ui *UI) FocusNext(dir int) {
+	ui.showFocus = true
+	ui.popup = nil
+
+	var order []Widget
+	if ui.boardFocus && !ui.Modal() {
+		order = append(order, nil)
+	}
+	for _, w := range ui.targets() {
+		if w.Enabled() {
+			order = append(order, w)
+		}
+	}
+	if len(order) == 0 {
+		return
+	}
+
+	current := -1
+	for i, w := range order {
+		if w == ui.focused {
+			current = i
+			break
+		}
+	}
+	if current == -1 && dir < 0 {
+		current = 0
+	}
+	ui.focused = order[(current+dir+len(order))%len(order)]
+}
+
+// Focus gives w keyboard focus, or takes it away from every widget when w is nil
+func (ui *UI) Focus(w Widget) {
+	ui.focused = w
+	ui.popup = nil
+}
+
+func (ui *UI) Focused() Widget {
+	return ui.focused
+}
+
+// Typing reports whether the focused widget takes text, so single key shortcuts should be ignored
+func (ui *UI) Typing() bool {
+	_, ok := ui.focused.(*TextInput)
+	return ok
+}
+
+// Highlighted reports whether w should be drawn as hovered: the mouse is over it, or it has focus while the
+// keyboard is in use
+func (ui *UI) Highlighted(w Widget) bool {
+	if !w.Enabled() {
+		return false
+	}
+	return (w == ui.hovered && ui.active == nil) || w == ui.active || (w == ui.focused && ui.showFocus)
+}
+
+// ShowDialog opens d on top of the screen. Until it is closed, only its buttons receive input.
+func (ui *UI) ShowDialog(d *Dialog) {
+	d.previousFocus = ui.focused
+	ui.dialogs = append(ui.dialogs, d)
+	ui.popup = nil
+	ui.active = nil
+	d.layout(ui)
+	ui.focused = nil
+	if len(d.buttons) > 0 {
+		ui.focused = d.buttons[0]
+	}
+}
+
+// CloseDialog closes d, returning focus to wherever it was before d opened
+func (ui *UI) CloseDialog(d *Dialog) {
+	for i := range ui.dialogs {
+		if ui.dialogs[i] == d {
+			ui.dialogs = append(ui.dialogs[:i], ui.dialogs[i+1:]...)
+			ui.focused = d.previousFocus
+			ui.active = nil
+			return
+		}
+	}
+}
+
+// Modal reports whether a dialog is open
+func (ui *UI) Modal() bool {
+	return len(ui.dialogs) > 0
+}
+
+// Draw draws the widgets, then any open dropdown, then the dialogs on top
+func (ui *UI) Draw(dst *ebiten.Image) {
+	for _, w := range ui.widgets {
+		w.Draw(dst, ui)
+	}
+	if ui.popup != nil && len(ui.dialogs) == 0 {
+		ui.popup.DrawPopup(dst, ui)
+	}
+	for _, d := range ui.dialogs {
+		d.Draw(dst, ui)
+	}
+	if ui.popup != nil && len(ui.dialogs) > 0 {
+		ui.popup.DrawPopup(dst, ui)
+	}
+}
+
+// drawBox draws a filled rect with a border, brighter when highlighted
+func (ui *UI) drawBox(dst *ebiten.Image, r Rect, highlighted bool) {
+	border := colornames.Gray
+	if highlighted {
+		border = colornames.Whitesmoke
+	}
+	thickness := 3 * ui.theme.Scale
+	ebitenutil.DrawRect(dst, r.X, r.Y, r.W, r.H, border)
+	ebitenutil.DrawRect(dst, r.X+thickness, r.Y+thickness, r.W-2*thickness, r.H-2*thickness, widgetFillColor)
+}
+
+// textPosIn returns the dot (left edge and baseline) to draw s at so it is centered in r
+func textPosIn(face font.Face, s string, r Rect) (int, int) {
+	bounds := text.BoundString(face, s)
+	x := r.X + (r.W-float64(bounds.Dx()))/2 - float64(bounds.Min.X)
+	y := r.Y + (r.H-float64(bounds.Dy()))/2 - float64(bounds.Min.Y)
+	return int(x), int(y)
+}
+
+// textLeftIn returns the dot to draw s at so it sits against the left edge of r, vertically centered
+func (ui *UI) textLeftIn(s string, r Rect) (int, int) {
+	_, y := textPosIn(ui.theme.Font, s, r)
+	return int(r.X + 12*ui.theme.Scale), y
+}
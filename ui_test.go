@@ -0,0 +1,112 @@
+package main
+
+import (
+	"golang.org/x/image/font/basicfont"
+	"testing"
+)
+
+// newTestUI is a UI at the design resolution with widgets laid out in rects, ready to receive input without a
+// window. The built in font stands in for the game's, so dialogs can be laid out.
+func newTestUI(widgets ...Widget) *UI {
+	ui := NewUI(&Theme{Scale: 1, Font: basicfont.Face7x13}, false)
+	ui.Resize(Rect{W: Width, H: Height})
+	ui.Add(widgets...)
+	return ui
+}
+
+func TestSlider(t *testing.T) {
+	var changes []float64
+	s := NewSlider(0, 1, 0.1, 0.5, func(value float64) {
+		changes = append(changes, value)
+	})
+	s.SetBounds(Rect{X: 100, Y: 100, W: 200, H: 40})
+	s.SetFormat(func(value float64) string {
+		return "format"
+	})
+	ui := newTestUI(s)
+
+	tests := []struct {
+		name    string
+		do      func()
+		want    float64
+		changed bool
+	}{
+		{"press snaps to a step", func() { s.Press(ui, 100+62, 120) }, 0.3, true},
+		{"drag past the end is clamped", func() { s.Drag(ui, 400, 120) }, 1, true},
+		{"drag to the same value", func() { s.Drag(ui, 299, 120) }, 1, false},
+		{"scroll down a step", func() { s.Scroll(ui, -1) }, 0.9, true},
+		{"scroll up a step", func() { s.Scroll(ui, 1) }, 1, true},
+		{"SetValue doesn't call onChange", func() { s.SetValue(0.04) }, 0, false},
+		{"SetValue is clamped", func() { s.SetValue(-3) }, 0, false},
+	}
+	for _, tt := range tests {
+		changes = changes[:0]
+		tt.do()
+		if diff := s.Value() - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: Value() = %v, want %v", tt.name, s.Value(), tt.want)
+		}
+		if changed := len(changes) > 0; changed != tt.changed {
+			t.Errorf("%s: onChange called %d times, want a call: %v", tt.name, len(changes), tt.changed)
+		}
+	}
+	if s.format == nil || s.format(s.Value()) != "format" {
+		t.Errorf("SetFormat didn't set the label format")
+	}
+}
+
+func TestList(t *testing.T) {
+	var selected, activated []int
+	l := NewList([]string{"a", "b", "c", "d", "e", "f"}, func(index int) {
+		selected = append(selected, index)
+	})
+	l.SetOnActivate(func(index int) {
+		activated = append(activated, index)
+	})
+	// room for 3 rows
+	l.SetBounds(Rect{X: 0, Y: 0, W: 200, H: 3 * ListRowHeight})
+	ui := newTestUI(l)
+	rowY := func(row int) int {
+		return row*ListRowHeight + ListRowHeight/2
+	}
+
+	l.Press(ui, 10, rowY(1))
+	if l.Selected() != 1 || len(selected) != 1 || len(activated) != 0 {
+		t.Errorf("pressing row 1 selected %d, onSelect %v and onActivate %v, want 1, [1] and []", l.Selected(), selected,
+			activated)
+	}
+	l.Press(ui, 10, rowY(1))
+	if len(selected) != 1 || len(activated) != 1 || activated[0] != 1 {
+		t.Errorf("pressing the selected row again: onSelect %v, onActivate %v, want [1] and [1]", selected, activated)
+	}
+	// below the last row that fits
+	l.Press(ui, 10, rowY(3))
+	if l.Selected() != 1 {
+		t.Errorf("pressing below the rows selected %d, want it left at 1", l.Selected())
+	}
+
+	l.Scroll(ui, -1)
+	l.Scroll(ui, -1)
+	l.Scroll(ui, -1)
+	l.Scroll(ui, -1)
+	if l.scroll != 3 {
+		t.Errorf("scrolled to row %d, want the scroll stopped at 3 so the last rows fill the list", l.scroll)
+	}
+	l.Press(ui, 10, rowY(2))
+	if l.Selected() != 5 {
+		t.Errorf("pressing the third visible row selected %d, want 5", l.Selected())
+	}
+
+	l.Select(0)
+	if l.scroll != 0 || len(selected) != 2 {
+		t.Errorf("Select(0) scrolled to %d and called onSelect %v, want 0 and no new call", l.scroll, selected)
+	}
+	l.Select(4)
+	if l.scroll != 2 {
+		t.Errorf("Select(4) scrolled to %d, want 2 to bring it into view", l.scroll)
+	}
+
+	l.SetItems([]string{"a", "b"})
+	if l.Selected() != -1 || l.scroll != 0 {
+		t.Errorf("SetItems with fewer rows left selection %d and scroll %d, want -1 and 0", l.Selected(), l.scroll)
+	}
+}